@@ -1,11 +1,13 @@
 package main
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/Merith-TK/tcg-cardgen/pkg/cardgen"
@@ -14,22 +16,109 @@ import (
 
 func main() {
 	var (
-		templateDir   = flag.String("template-dir", "", "Custom template directory")
 		outputDir     = flag.String("output-dir", "", "Custom output directory (default: .tcg-cardgen-out)")
+		outputRoot    = flag.String("output-root", "", "Write all outputs under this directory, mirroring the input tree")
 		validateOnly  = flag.Bool("validate-only", false, "Validate cards without generating")
+		stats         = flag.String("stats", "", "Print set statistics (rarity, type, cardstyle, missing art) instead of generating: text or json")
 		listTemplates = flag.Bool("list-templates", false, "List available templates")
+		listFonts     = flag.Bool("list-fonts", false, "List font families available to font.family")
+		initStyle     = flag.String("init", "", "Scaffold templates/<tcg>/<style>.yaml, its icons folder and a sample card.md here (e.g. mtg/mystyle)")
+		check         = flag.String("check", "", "Render a sample card with each comma-separated tcg/cardstyle (or \"all\") and report errors")
 		verbose       = flag.Bool("verbose", false, "Verbose output")
+		quiet         = flag.Bool("quiet", false, "Suppress all output except errors")
+		manifestPath  = flag.String("manifest", "", "Write a JSON manifest of generated outputs to this path")
+		summary       = flag.Bool("summary", false, "Print a summary of the run: time, cards rendered/validated/skipped/failed, slowest card and cache hit rates")
+		summaryJSON   = flag.String("summary-json", "", "Write the run summary as JSON to this path")
+		contactSheet  = flag.String("contact-sheet", "", "Write a PNG contact sheet of all generated cards to this path")
+		contactCols   = flag.Int("contact-columns", 5, "Number of columns in the contact sheet")
+		proof         = flag.Bool("proof", false, "Also write *-proof.png with bleed, trim and safe zone guides")
+		cropMarks     = flag.Bool("crop-marks", false, "Also write *-crop.png with corner crop marks in the bleed")
+		thumbnail     = flag.Int("thumbnail", 0, "Also write *.thumb.png scaled to this width in pixels")
+		serialize     = flag.Int("serialize", 0, "Render N numbered copies of each card (card.print_this 1..N) as name-01.png, name-02.png, ...")
+		svg           = flag.Bool("svg", false, "Also write *.svg with vector text (fonts embedded) and embedded images")
+		strict        = flag.Bool("strict", false, "Treat template warnings (e.g. duplicate layer names) as errors")
+		strictTCG     = flag.Bool("strict-tcg", false, "Fail cards whose card.tcg is missing or unknown instead of defaulting to mtg")
+		strictImages  = flag.Bool("strict-images", false, "Fail cards with images or icons that can't be loaded instead of drawing placeholders")
+		filter        = flag.String("filter", "", "Only generate cards meeting this condition, e.g. \"card.rarity == rare\" or \"card.set in [ALP, BET]\"")
+		lang          = flag.String("lang", "", "Language section to render for localized cards (e.g. en, fr)")
+		emojiFont     = flag.String("emoji-font", "", "TTF font drawn for emoji and other characters a layer's font lacks (monochrome outline fonts only)")
+		seed          = flag.Int64("seed", 0, "Seed for deterministic placeholder colors and other randomness")
+		expandEnv     = flag.Bool("expand-env", false, "Expand ${VAR} environment references in image sources")
+		diffRef       = flag.String("diff", "", "Compare the rendered card against this reference PNG; writes name.diff.png and fails on mismatch")
+		diffTolerance = flag.Int("diff-tolerance", 2, "Per-channel difference (0-255) ignored by --diff")
+		onlyLayers    = flag.String("only-layers", "", "Render only these comma-separated layers (e.g. artwork,title)")
+		skipLayers    = flag.String("skip-layers", "", "Leave these comma-separated layers out of the render")
+		toStdout      = flag.Bool("stdout", false, "Write the rendered PNG to stdout instead of a file (single card)")
+		toBase64      = flag.Bool("stdout-base64", false, "Write the rendered PNG to stdout as base64 (single card)")
+		resample      = flag.String("resample", "bilinear", "Image scaling quality: nearest (fast previews), bilinear or catmullrom (final)")
+		sharpen       = flag.Bool("sharpen", false, "Apply a mild unsharp mask to downscaled images")
+		scale         = flag.Float64("scale", 1, "Render at this multiple of the template dimensions (e.g. 2 for @2x)")
+		layerCache    = flag.Bool("layer-cache", false, "Reuse layers that draw identically on several cards (faster for large sets)")
+		srgb          = flag.Bool("srgb", false, "Tag card PNGs with the sRGB color space so printers don't color-shift them")
+		phFill        = flag.String("placeholder-fill", "", "Fill color for missing-image placeholders (default: light gray)")
+		phBorder      = flag.String("placeholder-border", "", "Border color for missing-image placeholders, or \"none\"")
+		phText        = flag.String("placeholder-text", "", "Label color for missing-image placeholders")
+		phLabel       = flag.Bool("placeholder-label", true, "Label missing-image placeholders with the missing file name")
 	)
+	var templateDirs dirList
+	flag.Var(&templateDirs, "template-dir", "Custom template directory; repeat or comma-separate for several (earlier wins)")
+	vars := make(varMap)
+	flag.Var(vars, "var", "Set a template variable for every card, overriding the card (key=value, repeatable)")
 	flag.Parse()
 
 	if *listTemplates {
 		// Initialize template manager to discover cardstyles
 		generator := cardgen.NewGenerator(&types.Config{
-			TemplateDir: *templateDir,
+			TemplateDirs: templateDirs,
+			Verbose:      *verbose,
+			Quiet:        *quiet,
 		})
 
 		if err := listAvailableCardstyles(generator); err != nil {
-			log.Fatalf("Error listing templates: %v", err)
+			generator.Logger().Fatalf("listing templates: %v", err)
+		}
+		return
+	}
+
+	if *listFonts {
+		generator := cardgen.NewGenerator(&types.Config{
+			TemplateDirs: templateDirs,
+			Verbose:      *verbose,
+			Quiet:        *quiet,
+		})
+
+		listAvailableFonts(generator)
+		return
+	}
+
+	if *initStyle != "" {
+		generator := cardgen.NewGenerator(&types.Config{
+			TemplateDirs: templateDirs,
+			Verbose:      *verbose,
+			Quiet:        *quiet,
+		})
+
+		if err := initWorkspace(generator, *initStyle); err != nil {
+			generator.Logger().Fatalf("initializing cardstyle: %v", err)
+		}
+		return
+	}
+
+	if *check != "" {
+		generator := cardgen.NewGenerator(&types.Config{
+			TemplateDirs: templateDirs,
+			Verbose:      *verbose,
+			Quiet:        *quiet,
+			Strict:       *strict,
+			StrictImages: *strictImages,
+			Seed:         *seed,
+			ExpandEnv:    *expandEnv,
+			EmojiFont:    *emojiFont,
+			Variables:    vars,
+		})
+
+		if err := checkCardstyles(generator, *check); err != nil {
+			generator.Logger().Fatalf("checking cardstyles: %v", err)
 		}
 		return
 	}
@@ -43,19 +132,189 @@ func main() {
 
 	inputPath := args[0]
 
+	// Mirrored output paths are relative to the input directory
+	inputRoot := inputPath
+	if info, err := os.Stat(inputPath); err == nil && !info.IsDir() {
+		inputRoot = filepath.Dir(inputPath)
+	}
+
 	// Initialize the card generator
 	generator := cardgen.NewGenerator(&types.Config{
-		TemplateDir:  *templateDir,
-		OutputDir:    *outputDir,
-		ValidateOnly: *validateOnly,
-		Verbose:      *verbose,
+		TemplateDirs:   templateDirs,
+		OutputDir:      *outputDir,
+		OutputRoot:     *outputRoot,
+		InputRoot:      inputRoot,
+		ValidateOnly:   *validateOnly,
+		Verbose:        *verbose,
+		Quiet:          *quiet,
+		Proof:          *proof,
+		CropMarks:      *cropMarks,
+		ThumbnailWidth: *thumbnail,
+		SVG:            *svg,
+		Serialize:      *serialize,
+		Strict:         *strict,
+		StrictImages:   *strictImages,
+		StrictTCG:      *strictTCG,
+		Language:       *lang,
+		Filter:         *filter,
+		EmojiFont:      *emojiFont,
+		Seed:           *seed,
+		ExpandEnv:      *expandEnv,
+		EmbedSRGB:      *srgb,
+		OnlyLayers:     splitList(*onlyLayers),
+		SkipLayers:     splitList(*skipLayers),
+		Resample:       *resample,
+		Sharpen:        *sharpen,
+		Scale:          *scale,
+		LayerCache:     *layerCache,
+		Variables:      vars,
+
+		PlaceholderFill:      *phFill,
+		PlaceholderBorder:    *phBorder,
+		PlaceholderTextColor: *phText,
+		PlaceholderNoLabel:   !*phLabel,
 	})
 
-	// Process input
+	// Set overview: tally the cards instead of rendering them
+	if *stats != "" {
+		if err := printStats(generator, inputPath, *stats); err != nil {
+			generator.Logger().Fatalf("analyzing cards: %v", err)
+		}
+		return
+	}
+
+	// Editor integration: hand the image back on stdout instead of writing a file
+	if *toStdout || *toBase64 {
+		if err := writeToStdout(generator, inputPath, *toBase64); err != nil {
+			generator.Logger().Fatalf("rendering to stdout: %v", err)
+		}
+		return
+	}
+
+	// Process input; the summary also covers a run stopped by a failing card
 	err := processInput(generator, inputPath)
+	if *summary {
+		printSummary(generator.Summary())
+	}
+	if *summaryJSON != "" {
+		if err := generator.WriteSummary(*summaryJSON); err != nil {
+			generator.Logger().Fatalf("writing summary: %v", err)
+		}
+	}
 	if err != nil {
-		log.Fatalf("Error processing input: %v", err)
+		generator.Logger().Fatalf("processing input: %v", err)
+	}
+
+	// Write manifest of generated outputs
+	if *manifestPath != "" {
+		if err := generator.WriteManifest(*manifestPath); err != nil {
+			generator.Logger().Fatalf("writing manifest: %v", err)
+		}
+	}
+
+	// Tile all generated cards into a single review image
+	if *contactSheet != "" {
+		if err := generator.WriteContactSheet(*contactSheet, *contactCols); err != nil {
+			generator.Logger().Fatalf("writing contact sheet: %v", err)
+		}
+	}
+
+	// Regression check against a committed reference image
+	if *diffRef != "" {
+		if *diffTolerance < 0 || *diffTolerance > 255 {
+			generator.Logger().Fatalf("diff tolerance must be between 0 and 255")
+		}
+		comparison, diffPath, err := generator.CompareWithReference(*diffRef, uint8(*diffTolerance))
+		if err != nil {
+			generator.Logger().Fatalf("comparing with reference: %v", err)
+		}
+		if !comparison.Matches() {
+			generator.Logger().Fatalf("card differs from %s: %.3f%% of pixels (%d), see %s",
+				*diffRef, comparison.Percent(), comparison.DiffPixels, diffPath)
+		}
+		generator.Logger().Infof("Matches reference %s", *diffRef)
+	}
+}
+
+// dirList collects a repeatable, comma-separated directory flag
+type dirList []string
+
+func (d *dirList) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *dirList) Set(value string) error {
+	// An existing directory is taken whole, even if its path has a comma
+	if info, err := os.Stat(value); err == nil && info.IsDir() {
+		*d = append(*d, value)
+		return nil
+	}
+	for _, dir := range strings.Split(value, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			*d = append(*d, dir)
+		}
+	}
+	return nil
+}
+
+// varMap collects repeatable key=value variable flags
+type varMap map[string]string
+
+func (v varMap) String() string {
+	pairs := make([]string, 0, len(v))
+	for key, value := range v {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v varMap) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
 	}
+	v[key] = val
+	return nil
+}
+
+// writeToStdout renders a single card file to stdout as raw PNG or base64.
+// Progress messages move to stderr so they can't corrupt the image data.
+func writeToStdout(generator *cardgen.Generator, inputPath string, encode bool) error {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return fmt.Errorf("cannot access %s: %v", inputPath, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory; stdout output takes a single card file", inputPath)
+	}
+
+	generator.Logger().SetOutput(os.Stderr)
+	if !encode {
+		return generator.RenderCardTo(inputPath, os.Stdout)
+	}
+
+	encoder := base64.NewEncoder(base64.StdEncoding, os.Stdout)
+	if err := generator.RenderCardTo(inputPath, encoder); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	_, err = fmt.Println()
+	return err
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func processInput(generator *cardgen.Generator, inputPath string) error {
@@ -86,10 +345,137 @@ func processDirectory(generator *cardgen.Generator, dirPath string) error {
 }
 
 func processFile(generator *cardgen.Generator, filePath string) error {
-	fmt.Printf("Processing: %s\n", filePath)
+	generator.Logger().Infof("Processing: %s", filePath)
 	return generator.GenerateCard(filePath)
 }
 
+// checkCardstyles renders a sample card with each named cardstyle ("tcg/style",
+// a bare TCG for its default, or "all") and fails if any of them can't render
+func checkCardstyles(generator *cardgen.Generator, names string) error {
+	var targets []string
+	if names == "all" {
+		cardstyles, err := generator.ListCardstyles()
+		if err != nil {
+			return fmt.Errorf("failed to discover cardstyles: %v", err)
+		}
+		for _, style := range cardstyles {
+			targets = append(targets, style.TCG+"/"+style.Name)
+		}
+	} else {
+		targets = splitList(names)
+	}
+
+	failed := 0
+	for _, target := range targets {
+		tcg, cardstyle, _ := strings.Cut(target, "/")
+		if err := generator.CheckCardstyle(tcg, cardstyle); err != nil {
+			generator.Logger().Errorf("%s: %v", target, err)
+			failed++
+			continue
+		}
+		generator.Logger().Infof("✓ %s renders", target)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d cardstyles failed", failed, len(targets))
+	}
+	return nil
+}
+
+// initWorkspace scaffolds a "tcg/style" cardstyle and sample card in the
+// working directory and explains how to render it
+func initWorkspace(generator *cardgen.Generator, name string) error {
+	tcg, cardstyle, _ := strings.Cut(name, "/")
+	created, err := generator.InitWorkspace(".", tcg, cardstyle)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Created:")
+	for _, path := range created {
+		fmt.Printf("  %s\n", path)
+	}
+	fmt.Println()
+	fmt.Println("Render the sample card with:")
+	fmt.Printf("  %s --template-dir templates card.md\n", filepath.Base(os.Args[0]))
+	fmt.Println()
+	fmt.Printf("Then edit templates/%s/%s.yaml and card.md and render again.\n", tcg, cardstyle)
+	return nil
+}
+
+// printStats prints the set statistics for a directory of cards as a
+// readable summary or as JSON
+func printStats(generator *cardgen.Generator, dir string, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown stats format %q (expected text or json)", format)
+	}
+
+	stats, err := generator.AnalyzeDirectory(dir)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode stats: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Cards: %d\n", stats.Cards)
+	printTally("By rarity", stats.ByRarity)
+	printTally("By type", stats.ByType)
+	printTally("By cardstyle", stats.ByCardStyle)
+
+	fmt.Println()
+	fmt.Printf("Missing art: %d\n", len(stats.MissingArt))
+	for _, path := range stats.MissingArt {
+		fmt.Printf("  %s\n", path)
+	}
+	if len(stats.Skipped) > 0 {
+		fmt.Printf("Skipped (not cards): %d\n", len(stats.Skipped))
+	}
+	return nil
+}
+
+// printSummary prints the timing, outcome and cache statistics of a run
+func printSummary(summary types.BatchSummary) {
+	fmt.Printf("Cards: %d in %.2fs (%d rendered, %d validated, %d skipped, %d failed)\n",
+		summary.Cards, summary.Seconds, summary.Rendered, summary.Validated, summary.Skipped, summary.Failed)
+	if summary.Slowest != "" {
+		fmt.Printf("Slowest: %s (%.2fs)\n", summary.Slowest, summary.SlowestSeconds)
+	}
+	printCacheStats("Image cache", summary.ImageCache)
+	printCacheStats("Template cache", summary.TemplateCache)
+}
+
+// printCacheStats prints one cache's hit rate
+func printCacheStats(name string, stats types.CacheStats) {
+	fmt.Printf("%s: %.0f%% hits (%d hits, %d misses)\n", name, 100*stats.HitRate(), stats.Hits, stats.Misses)
+}
+
+// printTally prints one statistics group, most common value first
+func printTally(title string, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Println()
+	fmt.Printf("%s:\n", title)
+	for _, key := range keys {
+		fmt.Printf("  %-24s %d\n", key, counts[key])
+	}
+}
+
 func listAvailableCardstyles(generator *cardgen.Generator) error {
 	cardstyles, err := generator.ListCardstyles()
 	if err != nil {
@@ -117,6 +503,9 @@ func listAvailableCardstyles(generator *cardgen.Generator) error {
 			if style.DisplayName != "" && style.DisplayName != style.Name {
 				fmt.Printf(" (%s)", style.DisplayName)
 			}
+			if style.Default {
+				fmt.Print(" [default]")
+			}
 			fmt.Println()
 
 			if style.Description != "" {
@@ -127,7 +516,7 @@ func listAvailableCardstyles(generator *cardgen.Generator) error {
 				fmt.Printf("     Extends: %s\n", style.Extends)
 			}
 
-			if style.Source != "built-in" {
+			if style.Source != "embedded" {
 				fmt.Printf("     Source: %s\n", style.Source)
 			}
 
@@ -137,3 +526,25 @@ func listAvailableCardstyles(generator *cardgen.Generator) error {
 
 	return nil
 }
+
+// listAvailableFonts prints each font family with its styles and the file
+// every style was loaded from
+func listAvailableFonts(generator *cardgen.Generator) {
+	fmt.Println("Available Fonts:")
+	fmt.Println()
+
+	fonts := generator.ListFonts()
+	for i, font := range fonts {
+		if i == 0 || fonts[i-1].Family != font.Family {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("🔤 %s\n", font.Family)
+		}
+		fmt.Printf("  %-12s %s\n", font.Style, font.Source)
+	}
+
+	fmt.Println()
+	fmt.Println("Unknown families and \"default\" use the built-in Go fonts, and \"mono\" uses")
+	fmt.Println("Go Mono. Missing italic styles are slanted from the upright face.")
+}
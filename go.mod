@@ -2,10 +2,9 @@ module github.com/Merith-TK/tcg-cardgen
 
 go 1.24.3
 
-require gopkg.in/yaml.v3 v3.0.1
-
 require (
-	github.com/fogleman/gg v1.3.0 // indirect
-	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
-	golang.org/x/image v0.32.0 // indirect
+	github.com/fogleman/gg v1.3.0
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
+	golang.org/x/image v0.32.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,27 @@
+package cardgen
+
+// Result is the outcome of generating a single card in a batch
+type Result struct {
+	Source string // Input card file
+	Output string // Rendered PNG path (empty in validate-only mode or on error)
+	Err    error
+}
+
+// GenerateCards generates every card in paths and returns a result per card.
+// Templates are loaded once per distinct cardstyle and the renderer (with its
+// image, font and background caches) is reused across the whole batch.
+// Failures are reported per card rather than stopping the batch.
+func (g *Generator) GenerateCards(paths []string) []Result {
+	results := make([]Result, len(paths))
+
+	for i, path := range paths {
+		output, err := g.generateCard(path)
+		results[i] = Result{
+			Source: path,
+			Output: output,
+			Err:    err,
+		}
+	}
+
+	return results
+}
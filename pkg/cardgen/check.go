@@ -0,0 +1,75 @@
+package cardgen
+
+import (
+	"fmt"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// sampleCoreFields are required fields the sample card fills through its
+// struct fields rather than its metadata
+var sampleCoreFields = map[string]bool{
+	"card.tcg":       true,
+	"card.cardstyle": true,
+	"card.title":     true,
+	"card.type":      true,
+	"card.rarity":    true,
+	"card.set":       true,
+	"card.artist":    true,
+}
+
+// CheckCardstyle renders a synthesized sample card with a cardstyle in memory
+// and returns any load, validation or render error, so broken templates are
+// caught without a real card file. An empty cardstyle checks the TCG's default.
+func (g *Generator) CheckCardstyle(tcg, cardstyle string) error {
+	template, err := g.templateManager.LoadTemplate(tcg, cardstyle)
+	if err != nil {
+		return fmt.Errorf("failed to load cardstyle: %v", err)
+	}
+	g.reportWarnings(template)
+
+	card := sampleCard(template, cardstyle)
+	if err := template.ValidateCard(card); err != nil {
+		return fmt.Errorf("sample card validation failed: %v", err)
+	}
+
+	if _, err := g.renderer.RenderImage(card, template); err != nil {
+		return fmt.Errorf("failed to render sample card: %v", err)
+	}
+	return nil
+}
+
+// sampleCard builds a card that satisfies the template's required fields.
+// Fields without a struct counterpart take their optional_fields default,
+// or "1" so they work as both text and numbers.
+func sampleCard(template *templates.Template, cardstyle string) *metadata.Card {
+	card := &metadata.Card{
+		TCG:        template.TCG,
+		CardStyle:  cardstyle,
+		Title:      "Sample Card",
+		Type:       "Sample Type",
+		Rarity:     "common",
+		Set:        "Sample Set",
+		Artist:     "Sample Artist",
+		PrintThis:  1,
+		PrintTotal: 1,
+		RulesText:  "Sample rules text.",
+		FlavorText: "Sample flavor text.",
+		Metadata:   make(map[string]interface{}),
+	}
+	card.Body = card.RulesText + "\n\n*" + card.FlavorText + "*"
+
+	for _, field := range template.Required {
+		if sampleCoreFields[field] {
+			continue
+		}
+		if value := template.Optional[field]; value != nil {
+			card.Metadata[field] = value
+		} else {
+			card.Metadata[field] = "1"
+		}
+	}
+
+	return card
+}
@@ -0,0 +1,27 @@
+package cardgen
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/renderer"
+)
+
+// WriteContactSheet tiles thumbnails of every card rendered so far into a single PNG
+func (g *Generator) WriteContactSheet(outputPath string, columns int) error {
+	items := make([]renderer.ContactSheetItem, 0, len(g.manifest))
+	for _, entry := range g.manifest {
+		base := filepath.Base(entry.Source)
+		items = append(items, renderer.ContactSheetItem{
+			ImagePath: entry.Output,
+			Label:     strings.TrimSuffix(base, filepath.Ext(base)),
+		})
+	}
+
+	if err := g.renderer.RenderContactSheet(items, columns, outputPath); err != nil {
+		return err
+	}
+
+	g.log.Infof("Contact sheet: %s (%d cards)", outputPath, len(items))
+	return nil
+}
@@ -0,0 +1,45 @@
+package cardgen
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/renderer"
+)
+
+// CompareWithReference compares the single card rendered so far against a
+// reference image. On a mismatch a diff image is written next to the card as
+// name.diff.png and its path returned.
+func (g *Generator) CompareWithReference(referencePath string, tolerance uint8) (renderer.Comparison, string, error) {
+	if len(g.manifest) != 1 {
+		return renderer.Comparison{}, "", fmt.Errorf("diff needs exactly one rendered card, got %d", len(g.manifest))
+	}
+	outputPath := g.manifest[0].Output
+
+	rendered, err := gg.LoadImage(outputPath)
+	if err != nil {
+		return renderer.Comparison{}, "", fmt.Errorf("cannot load rendered card %s: %v", outputPath, err)
+	}
+	reference, err := gg.LoadImage(referencePath)
+	if err != nil {
+		return renderer.Comparison{}, "", fmt.Errorf("cannot load reference %s: %v", referencePath, err)
+	}
+
+	comparison, err := renderer.Compare(rendered, reference, tolerance)
+	if err != nil {
+		return renderer.Comparison{}, "", err
+	}
+	diffPath := strings.TrimSuffix(outputPath, ".png") + ".diff.png"
+	if comparison.Matches() {
+		os.Remove(diffPath) // Drop a stale diff from an earlier mismatch
+		return comparison, "", nil
+	}
+
+	if err := g.renderer.SaveImage(comparison.Diff, diffPath); err != nil {
+		return comparison, "", err
+	}
+	return comparison, diffPath, nil
+}
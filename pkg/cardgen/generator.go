@@ -2,9 +2,15 @@ package cardgen
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Merith-TK/tcg-cardgen/pkg/logger"
 	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
 	"github.com/Merith-TK/tcg-cardgen/pkg/renderer"
 	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
@@ -17,103 +23,428 @@ type Generator struct {
 	templateManager *templates.Manager
 	metadataParser  *metadata.Parser
 	renderer        *renderer.Renderer
+	log             *logger.Logger
+	manifest        []types.ManifestEntry
+	warned          map[*templates.Template]bool
+	knownTCGs       []string // TCGs with at least one cardstyle, discovered on first use
+	transform       CardTransform
+	summary         types.BatchSummary // Card outcomes so far; timing and caches are filled in by Summary
+	batchStart      time.Time
+	batchEnd        time.Time
 }
 
+// CardTransform mutates a parsed card before its template is loaded, the card
+// is validated and rendered. Returning an error fails that card.
+type CardTransform func(card *metadata.Card) error
+
 // NewGenerator creates a new card generator with the given config
 func NewGenerator(config *types.Config) *Generator {
 	if config.OutputDir == "" {
 		config.OutputDir = ".tcg-cardgen-out"
 	}
 
-	return &Generator{
+	generator := &Generator{
 		config:          config,
-		templateManager: templates.NewManager(config.TemplateDir),
+		templateManager: templates.NewManager(config.TemplateDirs...),
 		metadataParser:  metadata.NewParser(),
 		renderer:        renderer.NewRenderer(),
+		log:             logger.New(logger.LevelFor(config.Verbose, config.Quiet)),
+		warned:          make(map[*templates.Template]bool),
 	}
+
+	generator.templateManager.SetStrict(config.Strict)
+	generator.metadataParser.SetLanguage(config.Language)
+	generator.metadataParser.SetStrictTCG(config.StrictTCG)
+
+	generator.renderer.SetLogger(generator.log)
+	generator.renderer.SetSeed(config.Seed)
+	generator.renderer.SetExpandEnv(config.ExpandEnv)
+	generator.renderer.SetStrictImages(config.StrictImages)
+	generator.renderer.SetEmbedSRGB(config.EmbedSRGB)
+	generator.renderer.SetLayerFilter(config.OnlyLayers, config.SkipLayers)
+	generator.renderer.SetVariables(config.Variables)
+	generator.renderer.SetLayerCache(config.LayerCache)
+	if err := generator.renderer.SetResampling(config.Resample, config.Sharpen); err != nil {
+		generator.log.Warnf("ignoring resample setting: %v", err)
+	}
+	if config.Scale != 0 {
+		if err := generator.renderer.SetScale(config.Scale); err != nil {
+			generator.log.Warnf("ignoring scale setting: %v", err)
+		}
+	}
+	if err := generator.renderer.SetPlaceholderStyle(renderer.PlaceholderStyle{
+		Fill:      config.PlaceholderFill,
+		Border:    config.PlaceholderBorder,
+		TextColor: config.PlaceholderTextColor,
+		HideLabel: config.PlaceholderNoLabel,
+	}); err != nil {
+		generator.log.Warnf("ignoring placeholder style: %v", err)
+	}
+
+	// Register global fonts so font.family resolves across all templates
+	for _, dir := range generator.templateManager.FontDirs() {
+		if err := generator.renderer.LoadFontDirectory(dir); err != nil {
+			generator.log.Warnf("failed to load fonts from %s: %v", dir, err)
+		}
+	}
+	if config.EmojiFont != "" {
+		if err := generator.renderer.LoadEmojiFont(config.EmojiFont); err != nil {
+			generator.log.Warnf("ignoring emoji font: %v", err)
+		}
+	}
+
+	return generator
+}
+
+// Logger returns the generator's leveled logger
+func (g *Generator) Logger() *logger.Logger {
+	return g.log
+}
+
+// RegisterFont registers an embedded font under a family name
+func (g *Generator) RegisterFont(name string, data []byte) error {
+	return g.renderer.RegisterFont(name, data)
 }
 
 // GenerateCard processes a single markdown file and generates a card
 func (g *Generator) GenerateCard(filePath string) error {
-	if g.config.Verbose {
-		fmt.Printf("Parsing metadata from: %s\n", filePath)
-	}
+	_, err := g.generateCard(filePath)
+	return err
+}
 
-	// Parse the markdown file
-	card, err := g.metadataParser.ParseFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to parse %s: %v", filePath, err)
-	}
+// SetCardTransform installs a hook run on every card right after parsing,
+// before template lookup, validation and rendering
+func (g *Generator) SetCardTransform(transform CardTransform) {
+	g.transform = transform
+}
 
-	if g.config.Verbose {
-		fmt.Printf("Card TCG: %s, CardStyle: %s, Title: %s\n", card.TCG, card.CardStyle, card.Title)
-	}
+// generateCard processes a single card file and returns its output path
+// (empty in validate-only mode)
+func (g *Generator) generateCard(filePath string) (output string, err error) {
+	started := time.Now()
+	skipped := false
+	defer func() {
+		g.recordCard(filePath, started, output, skipped, err)
+	}()
 
-	// Load appropriate template based on TCG and cardstyle
-	template, err := g.templateManager.LoadTemplate(card.TCG, card.CardStyle)
+	card, template, err := g.parseCard(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to load cardstyle %s/%s: %v", card.TCG, card.CardStyle, err)
+		return "", err
 	}
 
-	// Validate card against template
-	if err := template.ValidateCard(card); err != nil {
-		return fmt.Errorf("card validation failed: %v", err)
+	// Cards not matching --filter are left alone, not even validated
+	if g.config.Filter != "" && !g.renderer.MatchesCondition(card, template, g.config.Filter) {
+		g.log.Debugf("Skipping %s: doesn't match filter %q", filePath, g.config.Filter)
+		skipped = true
+		return "", nil
+	}
+
+	if err := g.validateCard(card, template); err != nil {
+		return "", err
 	}
 
 	if g.config.ValidateOnly {
-		fmt.Printf("✓ %s is valid\n", filePath)
-		return nil
+		// Flag text that won't fit its region at the template's font size
+		for _, overflow := range g.renderer.CheckTextOverflow(card, template) {
+			g.log.Warnf("%s: text in layer '%s' overflows its region (%.0fpx needed, %.0fpx available)",
+				filePath, overflow.Layer, overflow.Needed, overflow.Available)
+		}
+
+		g.log.Infof("✓ %s is valid", filePath)
+		return "", nil
 	}
 
 	// Create output directory
-	outputDir := filepath.Join(filepath.Dir(filePath), g.config.OutputDir)
+	outputDir := g.outputDirFor(filePath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
+		return "", fmt.Errorf("failed to create output directory: %v", err)
 	}
 
 	// Generate output filename
 	baseFilename := filepath.Base(filePath)
 	nameWithoutExt := baseFilename[:len(baseFilename)-len(filepath.Ext(baseFilename))]
-	outputPath := filepath.Join(outputDir, nameWithoutExt+".png")
 
-	if g.config.Verbose {
-		fmt.Printf("Output path: %s\n", outputPath)
+	if g.config.Serialize <= 0 {
+		return g.renderOutputs(card, template, outputDir, nameWithoutExt)
+	}
+
+	// Serialized run: one numbered copy per print, named name-01.png, name-02.png, ...
+	var firstOutput string
+	width := len(strconv.Itoa(g.config.Serialize))
+	for number := 1; number <= g.config.Serialize; number++ {
+		copyName := fmt.Sprintf("%s-%0*d", nameWithoutExt, width, number)
+		outputPath, err := g.renderOutputs(serialCopy(card, number, g.config.Serialize), template, outputDir, copyName)
+		if err != nil {
+			return "", err
+		}
+		if firstOutput == "" {
+			firstOutput = outputPath
+		}
 	}
+	return firstOutput, nil
+}
+
+// renderOutputs renders a card and writes name.png into outputDir, along
+// with the thumbnail, proof, crop mark and SVG outputs that are enabled. It returns
+// the PNG's path.
+func (g *Generator) renderOutputs(card *metadata.Card, template *templates.Template, outputDir, nameWithoutExt string) (string, error) {
+	filePath := card.SourceFile
+	outputPath := filepath.Join(outputDir, nameWithoutExt+".png")
+
+	g.log.Debugf("Output path: %s", outputPath)
 
 	// Render the card
-	if err := g.renderer.RenderCard(card, template, outputPath); err != nil {
-		return fmt.Errorf("failed to render card: %v", err)
+	img, err := g.renderer.RenderImage(card, template)
+	if err != nil {
+		return "", fmt.Errorf("failed to render card %s: %v", filePath, err)
+	}
+	if err := g.renderer.SaveImage(img, outputPath); err != nil {
+		return "", err
 	}
 
-	if g.config.Verbose {
-		fmt.Printf("✓ Generated: %s\n", outputPath)
+	g.recordOutput(card, template, outputPath)
+
+	// Downscaled copy for galleries, reusing the rendered image
+	if g.config.ThumbnailWidth > 0 {
+		thumbPath := filepath.Join(outputDir, nameWithoutExt+".thumb.png")
+		if err := g.renderer.SaveThumbnail(img, g.config.ThumbnailWidth, thumbPath); err != nil {
+			return "", fmt.Errorf("failed to write thumbnail: %v", err)
+		}
+		g.log.Debugf("✓ Thumbnail: %s", thumbPath)
+	}
+
+	// Write proofing overlay alongside the normal output
+	if g.config.Proof {
+		proofPath := filepath.Join(outputDir, nameWithoutExt+"-proof.png")
+		if err := g.renderer.RenderProof(card, template, proofPath); err != nil {
+			return "", fmt.Errorf("failed to render proof: %v", err)
+		}
+		g.log.Debugf("✓ Proof: %s", proofPath)
+	}
+
+	// Print copy with crop marks, reusing the rendered image
+	if g.config.CropMarks {
+		cropPath := filepath.Join(outputDir, nameWithoutExt+"-crop.png")
+		if err := g.renderer.SaveImage(g.renderer.AddCropMarks(img, template), cropPath); err != nil {
+			return "", fmt.Errorf("failed to write crop marks: %v", err)
+		}
+		g.log.Debugf("✓ Crop marks: %s", cropPath)
+	}
+
+	if g.config.SVG {
+		svgPath := filepath.Join(outputDir, nameWithoutExt+".svg")
+		if err := g.renderer.RenderSVG(card, template, svgPath); err != nil {
+			return "", fmt.Errorf("failed to render SVG: %v", err)
+		}
+		g.log.Debugf("✓ SVG: %s", svgPath)
+	}
+
+	if g.log.Enabled(logger.LevelDebug) {
+		g.log.Debugf("✓ Generated: %s", outputPath)
 	} else {
-		fmt.Printf("Generated: %s -> %s\n", filePath, outputPath)
+		g.log.Infof("Generated: %s -> %s", filePath, outputPath)
+	}
+
+	return outputPath, nil
+}
+
+// RenderCardTo renders a card file and writes the PNG to w instead of the
+// output directory, for tools that capture the image (e.g. editor previews)
+func (g *Generator) RenderCardTo(filePath string, w io.Writer) error {
+	card, template, err := g.loadCard(filePath)
+	if err != nil {
+		return err
 	}
 
+	if err := g.renderer.RenderCardToWriter(card, template, w); err != nil {
+		return fmt.Errorf("failed to render card %s: %v", filePath, err)
+	}
 	return nil
 }
 
+// loadCard parses a card file, loads its template and validates the card against it
+func (g *Generator) loadCard(filePath string) (*metadata.Card, *templates.Template, error) {
+	card, template, err := g.parseCard(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := g.validateCard(card, template); err != nil {
+		return nil, nil, err
+	}
+	return card, template, nil
+}
+
+// parseCard parses a card file and loads its template
+func (g *Generator) parseCard(filePath string) (*metadata.Card, *templates.Template, error) {
+	card, err := g.resolveCard(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g.log.Debugf("Card TCG: %s, CardStyle: %s, Title: %s", card.TCG, card.CardStyle, card.Title)
+
+	template, err := g.templateFor(card)
+	if err != nil {
+		return nil, nil, err
+	}
+	return card, template, nil
+}
+
+// validateCard reports the template's warnings and validates the card against it
+func (g *Generator) validateCard(card *metadata.Card, template *templates.Template) error {
+	g.reportWarnings(template)
+
+	if err := template.ValidateCard(card); err != nil {
+		return fmt.Errorf("card validation failed: %v", err)
+	}
+	return nil
+}
+
+// templateFor loads the template file a card names with card.template
+// (relative to the card file), or else its TCG and cardstyle's template
+func (g *Generator) templateFor(card *metadata.Card) (*templates.Template, error) {
+	if card.Template == "" {
+		template, err := g.templateManager.LoadTemplate(card.TCG, card.CardStyle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cardstyle %s/%s: %v", card.TCG, card.CardStyle, err)
+		}
+		return template, nil
+	}
+
+	path := card.Template
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(card.SourceFile), path)
+	}
+
+	template, err := g.templateManager.LoadTemplateFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load card template %s: %v", card.Template, err)
+	}
+
+	// The template stands in for the cardstyle, so a card may leave its TCG out
+	if card.TCG == "" {
+		card.TCG = template.TCG
+	}
+	return template, nil
+}
+
+// reportWarnings logs a template's load warnings the first time it's used
+func (g *Generator) reportWarnings(template *templates.Template) {
+	if g.warned[template] {
+		return
+	}
+	for _, warning := range template.Warnings {
+		g.log.Warnf("%s", warning)
+	}
+	g.warned[template] = true
+}
+
+// resolveCard parses a card file and resolves its cardstyle, applying the
+// card transform and falling back to the TCG's default cardstyle
+func (g *Generator) resolveCard(filePath string) (*metadata.Card, error) {
+	g.log.Debugf("Parsing metadata from: %s", filePath)
+
+	// Parse the markdown file
+	card, err := g.metadataParser.ParseFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filePath, err)
+	}
+
+	// Let embedders derive or normalize fields before anything reads them
+	if g.transform != nil {
+		if err := g.transform(card); err != nil {
+			return nil, fmt.Errorf("failed to transform %s: %v", filePath, err)
+		}
+	}
+
+	// Catch card.tcg typos before they surface as a confusing template error
+	if g.config.StrictTCG && card.Template == "" {
+		if err := g.checkTCG(card.TCG); err != nil {
+			return nil, fmt.Errorf("%s: %v", filePath, err)
+		}
+	}
+
+	// Cards that only name a TCG use its default cardstyle
+	if card.CardStyle == "" && card.Template == "" {
+		cardstyle, err := g.templateManager.DefaultCardstyle(card.TCG)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cardstyle for %s: %v", filePath, err)
+		}
+		card.CardStyle = cardstyle
+	}
+
+	return card, nil
+}
+
+// checkTCG fails for an empty TCG or one no discovered cardstyle belongs to,
+// listing the known TCGs
+func (g *Generator) checkTCG(tcg string) error {
+	if g.knownTCGs == nil {
+		cardstyles, err := g.templateManager.ListAvailableCardstyles()
+		if err != nil {
+			return fmt.Errorf("failed to discover cardstyles: %v", err)
+		}
+		seen := make(map[string]bool)
+		for _, style := range cardstyles {
+			if !seen[style.TCG] {
+				seen[style.TCG] = true
+				g.knownTCGs = append(g.knownTCGs, style.TCG)
+			}
+		}
+		sort.Strings(g.knownTCGs)
+	}
+
+	known := strings.Join(g.knownTCGs, ", ")
+	if tcg == "" {
+		return fmt.Errorf("card.tcg is not set (known TCGs: %s)", known)
+	}
+	for _, name := range g.knownTCGs {
+		if name == tcg {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown TCG %q (known TCGs: %s)", tcg, known)
+}
+
 // ListCardstyles discovers and lists all available cardstyles
 func (g *Generator) ListCardstyles() ([]types.CardStyleInfo, error) {
-	templateInfos, err := g.templateManager.ListAvailableCardstyles()
-	if err != nil {
-		return nil, err
+	return g.templateManager.ListAvailableCardstyles()
+}
+
+// ListFonts returns the font faces templates can reference by family,
+// followed by the built-in Go fonts used for any other family and Go Mono
+func (g *Generator) ListFonts() []types.FontInfo {
+	var fonts []types.FontInfo
+	for _, face := range g.renderer.Fonts() {
+		fonts = append(fonts, types.FontInfo{
+			Family: face.Family,
+			Style:  face.Style.String(),
+			Source: face.Source,
+		})
 	}
 
-	// Convert template CardStyleInfo to common types version
-	cardstyles := make([]types.CardStyleInfo, len(templateInfos))
-	for i, info := range templateInfos {
-		cardstyles[i] = types.CardStyleInfo{
-			TCG:         info.TCG,
-			Name:        info.Name,
-			DisplayName: info.DisplayName,
-			Description: info.Description,
-			Version:     info.Version,
-			Source:      info.Source,
-			Extends:     info.Extends,
+	for _, family := range []string{"Go", renderer.MonoFamily} {
+		for _, style := range []renderer.FontStyle{renderer.FontRegular, renderer.FontBold, renderer.FontItalic, renderer.FontBoldItalic} {
+			fonts = append(fonts, types.FontInfo{Family: family, Style: style.String(), Source: "built-in"})
 		}
 	}
+	return fonts
+}
+
+// outputDirFor returns the directory a card's outputs are written to. With an
+// output root the card's path under the input root is mirrored beneath it;
+// otherwise outputs go to OutputDir next to each card file.
+func (g *Generator) outputDirFor(filePath string) string {
+	if g.config.OutputRoot == "" {
+		return filepath.Join(filepath.Dir(filePath), g.config.OutputDir)
+	}
+
+	rel, err := filepath.Rel(g.config.InputRoot, filepath.Dir(filePath))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		// Cards outside the input root go directly into the output root
+		rel = ""
+	}
 
-	return cardstyles, nil
+	return filepath.Join(g.config.OutputRoot, rel)
 }
@@ -0,0 +1,209 @@
+package cardgen
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/renderer"
+	"github.com/Merith-TK/tcg-cardgen/pkg/types"
+)
+
+// specialTemplate is a minimal one-off template file for test cards
+const specialTemplate = `name: special
+tcg: pokemon
+dimensions: { width: 100, height: 140 }
+layers:
+  - name: title
+    type: text
+    content: "{{card.title}}"
+    region: { x: 0, y: 0, width: 100, height: 20 }
+`
+
+// writeFiles writes files into a temp directory and returns it
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestGenerateCards(t *testing.T) {
+	good := "---\ncard.tcg: mtg\ncard.cardstyle: basic\ncard.title: %s\ncard.type: Instant\n---\n\nDraw a card.\n"
+	dir := writeFiles(t, map[string]string{
+		"first.md":  fmt.Sprintf(good, "First"),
+		"broken.md": "---\ncard.tcg: mtg\ncard.cardstyle: missing\ncard.title: Broken\n---\n",
+		"last.md":   fmt.Sprintf(good, "Last"),
+	})
+	paths := []string{filepath.Join(dir, "first.md"), filepath.Join(dir, "broken.md"), filepath.Join(dir, "last.md")}
+
+	generator := NewGenerator(&types.Config{OutputDir: "out", Quiet: true})
+	results := generator.GenerateCards(paths)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	// The broken card fails on its own; the cards after it still render
+	for i, name := range []string{"first", "broken", "last"} {
+		result := results[i]
+		if result.Source != paths[i] {
+			t.Errorf("%s: source = %s, want %s", name, result.Source, paths[i])
+		}
+		if name == "broken" {
+			if result.Err == nil || result.Output != "" {
+				t.Errorf("broken: got output %q and error %v, want only an error", result.Output, result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("%s: %v", name, result.Err)
+			continue
+		}
+		if want := filepath.Join(dir, "out", name+".png"); result.Output != want {
+			t.Errorf("%s: output = %s, want %s", name, result.Output, want)
+		}
+		if _, err := os.Stat(result.Output); err != nil {
+			t.Errorf("%s: output not written: %v", name, err)
+		}
+	}
+}
+
+func TestRemoteArtworkFit(t *testing.T) {
+	magenta := color.RGBA{255, 0, 255, 255}
+	art := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for i := 0; i < len(art.Pix); i += 4 {
+		art.Pix[i], art.Pix[i+1], art.Pix[i+2], art.Pix[i+3] = magenta.R, magenta.G, magenta.B, magenta.A
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		png.Encode(w, art)
+	}))
+	defer server.Close()
+
+	// Downloads use the default transport, which must trust the test server
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	defer func() { http.DefaultTransport = transport }()
+
+	dir := t.TempDir()
+	cardPath := filepath.Join(dir, "remote.md")
+	card := "---\n" +
+		"card.tcg: mtg\n" +
+		"card.cardstyle: basic\n" +
+		"card.title: Remote Art\n" +
+		"card.type: Creature\n" +
+		"card.artwork: { url: \"" + server.URL + "/art.png\", fit: fit }\n" +
+		"---\n\n" +
+		"Flying\n"
+	if err := os.WriteFile(cardPath, []byte(card), 0644); err != nil {
+		t.Fatalf("failed to write card: %v", err)
+	}
+
+	generator := NewGenerator(&types.Config{OutputDir: "out", Quiet: true})
+	result := generator.GenerateCards([]string{cardPath})[0]
+	if result.Err != nil {
+		t.Fatalf("failed to generate card: %v", result.Err)
+	}
+
+	file, err := os.Open(result.Output)
+	if err != nil {
+		t.Fatalf("failed to open output: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
+
+	// The 2:1 art fits the 630x460 artwork region at 630x315, centered
+	if got := color.RGBAModel.Convert(img.At(375, 330)); got != magenta {
+		t.Errorf("artwork center = %v, want the downloaded art, not a placeholder", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(375, 120)); got == magenta {
+		t.Errorf("artwork top = %v, want the art letterboxed by fit", got)
+	}
+}
+
+func TestCardTemplateTCG(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"special.yaml": specialTemplate,
+		"one_off.md":   "---\ncard.title: One Off\ncard.template: ./special.yaml\n---\n",
+	})
+
+	generator := NewGenerator(&types.Config{OutputDir: "out", Quiet: true})
+	card, _, err := generator.loadCard(filepath.Join(dir, "one_off.md"))
+	if err != nil {
+		t.Fatalf("failed to load card: %v", err)
+	}
+	if card.TCG != "pokemon" {
+		t.Errorf("card.tcg = %q, want the template's pokemon", card.TCG)
+	}
+}
+
+func TestInitWorkspaceExisting(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"card.md": "# Mine\n"})
+
+	generator := NewGenerator(&types.Config{Quiet: true})
+	if _, err := generator.InitWorkspace(dir, "mtg", "mystyle"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("got error %v, want an already exists error", err)
+	}
+
+	// Nothing is created next to the existing card
+	if _, err := os.Stat(filepath.Join(dir, "templates")); !os.IsNotExist(err) {
+		t.Errorf("templates directory was created: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "card.md")); string(content) != "# Mine\n" {
+		t.Errorf("card.md was overwritten with %q", content)
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"special.yaml": specialTemplate,
+		"numbered.md":  "---\ncard.title: Numbered\ncard.template: ./special.yaml\ncard.print_this: 1\ncard.print_total: 5\n---\n",
+		"limited.md":   "---\ncard:\n  title: Limited\n  template: ./special.yaml\n  print_total: 50\n---\n",
+	})
+	generator := NewGenerator(&types.Config{OutputDir: "out", Serialize: 12, Quiet: true})
+
+	// The run size replaces a smaller total and keeps a larger one
+	for name, total := range map[string]string{"numbered": "12", "limited": "50"} {
+		card, template, err := generator.loadCard(filepath.Join(dir, name+".md"))
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", name, err)
+		}
+		for number := 1; number <= 12; number++ {
+			vars := renderer.NewVariableProcessor().BuildTemplateVariables(serialCopy(card, number, 12), template)
+			if vars["card.print_this"] != strconv.Itoa(number) || vars["card.print_total"] != total {
+				t.Errorf("%s copy %d: got %s/%s, want %d/%s", name, number, vars["card.print_this"], vars["card.print_total"], number, total)
+			}
+		}
+	}
+
+	// Copies are numbered to the width of the run size
+	outputDir := filepath.Join(dir, "out")
+	result := generator.GenerateCards([]string{filepath.Join(dir, "numbered.md")})[0]
+	if result.Err != nil {
+		t.Fatalf("failed to generate: %v", result.Err)
+	}
+	if want := filepath.Join(outputDir, "numbered-01.png"); result.Output != want {
+		t.Errorf("output = %s, want %s", result.Output, want)
+	}
+	for _, name := range []string{"numbered-01.png", "numbered-09.png", "numbered-12.png"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("copy %s not written: %v", name, err)
+		}
+	}
+}
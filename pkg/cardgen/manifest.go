@@ -0,0 +1,53 @@
+package cardgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+	"github.com/Merith-TK/tcg-cardgen/pkg/types"
+)
+
+// recordOutput adds a rendered card to the run manifest
+func (g *Generator) recordOutput(card *metadata.Card, template *templates.Template, outputPath string) {
+	entry := types.ManifestEntry{
+		Source:    card.SourceFile,
+		Output:    outputPath,
+		TCG:       card.TCG,
+		CardStyle: card.CardStyle,
+		Width:     template.Dimensions.Width,
+		Height:    template.Dimensions.Height,
+	}
+
+	if info, err := os.Stat(outputPath); err == nil {
+		entry.Bytes = info.Size()
+	}
+
+	g.manifest = append(g.manifest, entry)
+}
+
+// Manifest returns the entries for every card rendered so far
+func (g *Generator) Manifest() []types.ManifestEntry {
+	return g.manifest
+}
+
+// WriteManifest writes the manifest of rendered cards as a JSON array
+func (g *Generator) WriteManifest(path string) error {
+	entries := g.manifest
+	if entries == nil {
+		entries = []types.ManifestEntry{} // Always write an array, even when nothing was rendered
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %v", path, err)
+	}
+
+	return nil
+}
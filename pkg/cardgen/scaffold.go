@@ -0,0 +1,165 @@
+package cardgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// InitWorkspace scaffolds a starter cardstyle under dir: a
+// templates/<tcg>/<cardstyle>.yaml extending the TCG's default built-in
+// cardstyle, its icons folder and a sample card.md using it. It refuses to
+// overwrite: if the template or card already exists, nothing is created. It
+// returns the paths it created.
+func (g *Generator) InitWorkspace(dir, tcg, cardstyle string) ([]string, error) {
+	if tcg == "" || cardstyle == "" || strings.ContainsAny(tcg+cardstyle, `/\`) {
+		return nil, fmt.Errorf("expected tcg/cardstyle, got %q", tcg+"/"+cardstyle)
+	}
+
+	base, err := g.builtinBase(tcg)
+	if err != nil {
+		return nil, err
+	}
+	template, err := g.templateManager.LoadTemplate(tcg, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cardstyle %s/%s: %v", tcg, base, err)
+	}
+
+	templatePath := filepath.Join(dir, "templates", tcg, cardstyle+".yaml")
+	iconDir := filepath.Join(dir, "templates", tcg, "icons")
+	cardPath := filepath.Join(dir, "card.md")
+	for _, path := range []string{templatePath, cardPath} {
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("%s already exists", path)
+		}
+	}
+
+	var created []string
+	if _, err := os.Stat(iconDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(iconDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %v", iconDir, err)
+		}
+		created = append(created, iconDir+string(filepath.Separator))
+	}
+
+	if err := os.WriteFile(templatePath, []byte(starterTemplate(template, tcg, cardstyle, base)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", templatePath, err)
+	}
+	created = append(created, templatePath)
+
+	card, err := starterCard(template, tcg, cardstyle)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(cardPath, []byte(card), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", cardPath, err)
+	}
+	created = append(created, cardPath)
+
+	return created, nil
+}
+
+// builtinBase returns the built-in cardstyle a new TCG cardstyle extends:
+// the TCG's default one, or its first if none is marked default
+func (g *Generator) builtinBase(tcg string) (string, error) {
+	cardstyles, err := g.templateManager.ListAvailableCardstyles()
+	if err != nil {
+		return "", fmt.Errorf("failed to discover cardstyles: %v", err)
+	}
+
+	base := ""
+	for _, style := range cardstyles {
+		if style.TCG != tcg || style.Source != "embedded" {
+			continue
+		}
+		if style.Default {
+			return style.Name, nil
+		}
+		if base == "" {
+			base = style.Name
+		}
+	}
+
+	if base == "" {
+		return "", fmt.Errorf("no built-in cardstyle for TCG %s to extend", tcg)
+	}
+	return base, nil
+}
+
+// starterTemplate writes a cardstyle extending base with commented examples
+// of the usual customizations, listing the base's layers to override
+func starterTemplate(base *templates.Template, tcg, cardstyle, baseName string) string {
+	var layers []string
+	for _, layer := range base.Layers {
+		layers = append(layers, layer.Name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "name: %q\n", cardstyle)
+	fmt.Fprintf(&b, "tcg: %s\n", tcg)
+	b.WriteString("version: \"1.0.0\"\n")
+	fmt.Fprintf(&b, "description: \"Custom %s cardstyle\"\n", tcg)
+	fmt.Fprintf(&b, "extends: \"builtin:%s/%s\"\n", tcg, baseName)
+	b.WriteString("\n")
+	b.WriteString("# Everything not changed here comes from the built-in cardstyle.\n")
+	fmt.Fprintf(&b, "# Its layers: %s\n", strings.Join(layers, ", "))
+	b.WriteString("\n")
+	b.WriteString("# Adjust built-in layers by name; only the fields given change\n")
+	b.WriteString("overrides: []\n")
+	if len(layers) > 0 {
+		fmt.Fprintf(&b, "#  - layer: %q\n", layers[len(layers)-1])
+		b.WriteString("#    font: { color: \"#1a1a1a\" }\n")
+	}
+	b.WriteString("\n")
+	b.WriteString("# New layers drawn on top of the built-in ones\n")
+	b.WriteString("additional_layers: []\n")
+	b.WriteString("#  - name: \"watermark\"\n")
+	b.WriteString("#    type: \"text\"\n")
+	b.WriteString("#    region: { x: 60, y: 980, width: 630, height: 30 }\n")
+	b.WriteString("#    content: \"{{card.set}}\"\n")
+	b.WriteString("#    align: \"center\"\n")
+	b.WriteString("\n")
+	b.WriteString("# PNGs in the icons folder next to this file replace built-in icons of the\n")
+	b.WriteString("# same path, and new icons can be named here\n")
+	b.WriteString("icons: {}\n")
+	fmt.Fprintf(&b, "#  %s.star: \"{{icon_dir}}/star.png\"\n", tcg)
+	return b.String()
+}
+
+// starterCard writes a sample card using the new cardstyle, filling the
+// template's required fields like the --check sample card does
+func starterCard(template *templates.Template, tcg, cardstyle string) (string, error) {
+	sample := sampleCard(template, cardstyle)
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "card.tcg: %s\n", tcg)
+	fmt.Fprintf(&b, "card.cardstyle: %s\n", cardstyle)
+	b.WriteString("card.title: \"My First Card\"\n")
+	b.WriteString("card.type: \"Creature\"\n")
+	b.WriteString("card.rarity: common\n")
+	b.WriteString("card.artist: \"You\"\n")
+
+	for _, field := range template.Required {
+		value, exists := sample.Metadata[field]
+		if !exists {
+			continue
+		}
+		line, err := yaml.Marshal(map[string]interface{}{field: value})
+		if err != nil {
+			return "", fmt.Errorf("failed to write sample field %s: %v", field, err)
+		}
+		b.Write(line)
+	}
+
+	b.WriteString("---\n\n")
+	b.WriteString("# My First Card\n\n")
+	b.WriteString("Rules text goes here. **Bold** and *italic* markdown work.\n\n")
+	b.WriteString("*Flavor text goes last, in italics.*\n")
+	return b.String(), nil
+}
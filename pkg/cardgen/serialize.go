@@ -0,0 +1,48 @@
+package cardgen
+
+import (
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+)
+
+// serialCopy returns a copy of card numbered number of a serialized run.
+// The card's own card.print_total is kept when it's larger than the run
+// (e.g. rendering the first 10 of 50), otherwise the run size is the total.
+// Frontmatter values are replaced too, since they win over the parsed fields.
+func serialCopy(card *metadata.Card, number, count int) *metadata.Card {
+	total := count
+	if card.PrintTotal > count {
+		total = card.PrintTotal
+	}
+
+	numbered := *card
+	numbered.PrintThis = number
+	numbered.PrintTotal = total
+
+	numbered.Metadata = make(map[string]interface{}, len(card.Metadata))
+	for key, value := range card.Metadata {
+		numbered.Metadata[key] = value
+	}
+	if _, exists := numbered.Metadata["card.print_this"]; exists {
+		numbered.Metadata["card.print_this"] = number
+	}
+	if _, exists := numbered.Metadata["card.print_total"]; exists {
+		numbered.Metadata["card.print_total"] = total
+	}
+
+	// Nested form: card: { print_this: 1, print_total: 50 }
+	if cardMap, ok := card.Metadata["card"].(map[string]interface{}); ok {
+		nested := make(map[string]interface{}, len(cardMap))
+		for key, value := range cardMap {
+			nested[key] = value
+		}
+		if _, exists := nested["print_this"]; exists {
+			nested["print_this"] = number
+		}
+		if _, exists := nested["print_total"]; exists {
+			nested["print_total"] = total
+		}
+		numbered.Metadata["card"] = nested
+	}
+
+	return &numbered
+}
@@ -0,0 +1,109 @@
+package cardgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/types"
+)
+
+// noValue is the tally key for cards that leave a field empty
+const noValue = "(none)"
+
+// AnalyzeDirectory parses every card under dir, without loading templates
+// or rendering, and tallies them by rarity, type and cardstyle, listing the
+// cards whose artwork is missing
+func (g *Generator) AnalyzeDirectory(dir string) (types.SetStats, error) {
+	stats := types.SetStats{
+		ByRarity:    make(map[string]int),
+		ByType:      make(map[string]int),
+		ByCardStyle: make(map[string]int),
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		card, err := g.resolveCard(path)
+		if err != nil || (card.TCG == "" && card.Template == "") {
+			g.log.Debugf("Skipping %s: %v", path, err)
+			stats.Skipped = append(stats.Skipped, path)
+			return nil
+		}
+
+		stats.Cards++
+		stats.ByRarity[tallyKey(strings.ToLower(cardField(card, "rarity", card.Rarity)))]++
+		stats.ByType[tallyKey(cardField(card, "type", card.Type))]++
+		if card.Template != "" {
+			stats.ByCardStyle[card.Template]++
+		} else {
+			stats.ByCardStyle[card.TCG+"/"+card.CardStyle]++
+		}
+
+		if !g.hasArtwork(card) {
+			stats.MissingArt = append(stats.MissingArt, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to scan %s: %v", dir, err)
+	}
+
+	sort.Strings(stats.MissingArt)
+	sort.Strings(stats.Skipped)
+	return stats, nil
+}
+
+// cardField returns a card.* field as rendering sees it: a value in the
+// nested card: frontmatter map wins over the parsed struct field
+func cardField(card *metadata.Card, name, parsed string) string {
+	if cardMap, ok := card.Metadata["card"].(map[string]interface{}); ok {
+		if value, ok := cardMap[name].(string); ok && value != "" {
+			return value
+		}
+	}
+	return parsed
+}
+
+// tallyKey names the bucket a field value is counted in
+func tallyKey(value string) string {
+	if value = strings.TrimSpace(value); value == "" {
+		return noValue
+	}
+	return value
+}
+
+// hasArtwork reports whether a card sets card.artwork (as a string or a
+// { url: ... } map) and, for local files, whether the file exists. URLs are
+// assumed to be reachable.
+func (g *Generator) hasArtwork(card *metadata.Card) bool {
+	var artwork interface{} = card.Metadata["card.artwork"]
+	if cardMap, ok := card.Metadata["card"].(map[string]interface{}); ok && cardMap["artwork"] != nil {
+		artwork = cardMap["artwork"]
+	}
+	if artworkMap, ok := artwork.(map[string]interface{}); ok {
+		artwork = artworkMap["url"]
+	}
+
+	source, _ := artwork.(string)
+	if source == "" {
+		return false
+	}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return true
+	}
+	if g.config.ExpandEnv {
+		source = os.ExpandEnv(source)
+	}
+
+	_, err := os.Stat(source)
+	return err == nil
+}
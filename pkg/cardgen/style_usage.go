@@ -0,0 +1,51 @@
+package cardgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// CardsUsingStyle walks dir and returns the card files that resolve to the
+// given TCG and cardstyle, so the impact of a template change can be checked
+// before editing it. Cards without a cardstyle match the TCG's default one,
+// and an empty cardstyle selects that default. Cards with their own
+// card.template, and markdown files that aren't cards or fail to parse, are
+// skipped.
+func (g *Generator) CardsUsingStyle(dir, tcg, cardstyle string) ([]string, error) {
+	if cardstyle == "" {
+		defaultStyle, err := g.templateManager.DefaultCardstyle(tcg)
+		if err != nil {
+			return nil, err
+		}
+		cardstyle = defaultStyle
+	}
+
+	var matches []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		card, err := g.resolveCard(path)
+		if err != nil {
+			g.log.Debugf("Skipping %s: %v", path, err)
+			return nil
+		}
+
+		if card.Template == "" && card.TCG == tcg && card.CardStyle == cardstyle {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %v", dir, err)
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
@@ -0,0 +1,61 @@
+package cardgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/types"
+)
+
+// recordCard tallies a finished card for the batch summary
+func (g *Generator) recordCard(filePath string, started time.Time, output string, skipped bool, err error) {
+	if g.batchStart.IsZero() {
+		g.batchStart = started
+	}
+	g.batchEnd = time.Now()
+
+	summary := &g.summary
+	summary.Cards++
+	switch {
+	case err != nil:
+		summary.Failed++
+	case skipped:
+		summary.Skipped++
+	case output == "":
+		summary.Validated++
+	default:
+		summary.Rendered++
+	}
+
+	if seconds := g.batchEnd.Sub(started).Seconds(); seconds > summary.SlowestSeconds {
+		summary.Slowest, summary.SlowestSeconds = filePath, seconds
+	}
+}
+
+// Summary returns the timing, outcome and cache statistics of every card
+// generated so far
+func (g *Generator) Summary() types.BatchSummary {
+	summary := g.summary
+	if !g.batchStart.IsZero() {
+		summary.Seconds = g.batchEnd.Sub(g.batchStart).Seconds()
+	}
+	summary.ImageCache = g.renderer.ImageCacheStats()
+	summary.TemplateCache = g.templateManager.CacheStats()
+	return summary
+}
+
+// WriteSummary writes the batch summary as JSON
+func (g *Generator) WriteSummary(path string) error {
+	data, err := json.MarshalIndent(g.Summary(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write summary %s: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Level controls which messages a Logger emits
+type Level int
+
+const (
+	LevelError Level = iota // Errors only (-quiet)
+	LevelWarn
+	LevelInfo  // Default CLI output
+	LevelDebug // Verbose output (-verbose)
+)
+
+// LevelFor maps the CLI verbosity flags to a log level.
+// Quiet takes precedence over verbose.
+func LevelFor(verbose, quiet bool) Level {
+	switch {
+	case quiet:
+		return LevelError
+	case verbose:
+		return LevelDebug
+	default:
+		return LevelInfo
+	}
+}
+
+// Logger is a small leveled logger for CLI and generator output
+type Logger struct {
+	level  Level
+	out    io.Writer // info and debug messages
+	errOut io.Writer // warnings and errors
+}
+
+// New creates a logger writing info/debug to stdout and warnings/errors to stderr
+func New(level Level) *Logger {
+	return &Logger{
+		level:  level,
+		out:    os.Stdout,
+		errOut: os.Stderr,
+	}
+}
+
+// SetOutput redirects info and debug messages, e.g. to stderr when stdout
+// carries program output such as image data
+func (l *Logger) SetOutput(w io.Writer) {
+	l.out = w
+}
+
+// Level returns the logger's current level
+func (l *Logger) Level() Level {
+	return l.level
+}
+
+// Enabled reports whether messages at the given level are emitted
+func (l *Logger) Enabled(level Level) bool {
+	return level <= l.level
+}
+
+// Errorf logs an error message
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, l.errOut, "Error: "+format, args...)
+}
+
+// Warnf logs a warning message
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, l.errOut, "Warning: "+format, args...)
+}
+
+// Infof logs a regular progress message
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, l.out, format, args...)
+}
+
+// Debugf logs a verbose diagnostic message
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, l.out, format, args...)
+}
+
+// Fatalf logs an error message and exits with status 1
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.Errorf(format, args...)
+	os.Exit(1)
+}
+
+// logf writes a message if the level is enabled, adding a trailing newline if missing
+func (l *Logger) logf(level Level, w io.Writer, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	fmt.Fprint(w, msg)
+}
@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// manaSymbolPattern matches one braced mana symbol: "{W}", "{2/W}", or an icon
+// reference like "{{mtg.mana_white}}"
+var manaSymbolPattern = regexp.MustCompile(`\{\{?([^{}]+)\}?\}`)
+
+// manaIconPattern matches MTG icon references: "mtg.mana_white", "mtg.cost_colorless(2)"
+var manaIconPattern = regexp.MustCompile(`^(?:mtg|tcg)\.(?:mana|cost)_([a-z]+)(?:\((\w+)\))?$`)
+
+// manaColorSymbols maps icon color names to their mana symbol
+var manaColorSymbols = map[string]string{
+	"white": "W",
+	"blue":  "U",
+	"black": "B",
+	"red":   "R",
+	"green": "G",
+}
+
+// ParseManaCost splits a mana cost into its symbols, in order. It accepts
+// braced symbols ("{2}{W}{W}"), hybrid and phyrexian symbols ("{W/U}", "{G/P}"),
+// the mana icon references templates use ("{{mtg.mana_white}}") and brace-less
+// shorthand ("2WW"). Symbols are returned uppercase without braces.
+func ParseManaCost(cost string) []string {
+	cost = strings.TrimSpace(cost)
+	if cost == "" {
+		return nil
+	}
+
+	if !strings.Contains(cost, "{") {
+		return parseManaShorthand(cost)
+	}
+
+	var symbols []string
+	for _, match := range manaSymbolPattern.FindAllStringSubmatch(cost, -1) {
+		if symbol := manaSymbol(match[1]); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// manaSymbol normalizes the inside of one braced symbol
+func manaSymbol(token string) string {
+	token = strings.TrimSpace(token)
+	if match := manaIconPattern.FindStringSubmatch(token); match != nil {
+		if symbol, exists := manaColorSymbols[match[1]]; exists {
+			return symbol
+		}
+		if match[1] == "colorless" && match[2] != "" {
+			return strings.ToUpper(match[2]) // Generic cost, e.g. cost_colorless(2)
+		}
+		return ""
+	}
+	if strings.Contains(token, ".") {
+		return "" // Some other icon reference, e.g. {{mtg.tap}}
+	}
+	return strings.ToUpper(strings.ReplaceAll(token, " ", ""))
+}
+
+// parseManaShorthand splits brace-less costs: digit runs are one generic
+// symbol, every other character is a symbol of its own
+func parseManaShorthand(cost string) []string {
+	var symbols []string
+	var number strings.Builder
+	for _, r := range cost {
+		if unicode.IsDigit(r) {
+			number.WriteRune(r)
+			continue
+		}
+		if number.Len() > 0 {
+			symbols = append(symbols, number.String())
+			number.Reset()
+		}
+		if !unicode.IsSpace(r) {
+			symbols = append(symbols, string(unicode.ToUpper(r)))
+		}
+	}
+	if number.Len() > 0 {
+		symbols = append(symbols, number.String())
+	}
+	return symbols
+}
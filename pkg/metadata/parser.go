@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -20,6 +22,9 @@ type Card struct {
 	Rarity    string `yaml:"card.rarity"`
 	Set       string `yaml:"card.set"`
 	Artist    string `yaml:"card.artist"`
+	Language  string `yaml:"card.lang"`
+	Related   string `yaml:"card.related"`  // Path to a related card file (e.g. a token this card creates)
+	Template  string `yaml:"card.template"` // Template file used instead of the TCG's cardstyle, relative to the card
 
 	// Print information
 	PrintThis  int `yaml:"card.print_this"`
@@ -34,27 +39,69 @@ type Card struct {
 	// Raw metadata for template-specific fields
 	Metadata map[string]interface{} `yaml:",inline"`
 
+	// Parsed card referenced by Related, if any
+	RelatedCard *Card `yaml:"-"`
+
 	// Source file info
 	SourceFile string `yaml:"-"`
 }
 
+// defaultLanguage is used when neither the parser nor the card selects a language
+const defaultLanguage = "en"
+
+// languageHeader matches localized section headers like "## en" or "## pt-BR"
+// whose language is one of languageCodes
+var languageHeader = regexp.MustCompile(`^##\s+(([a-z]{2})(?:[-_][A-Za-z]{2,4})?)\s*$`)
+
+// languageCodes lists the ISO 639-1 language codes, so headings such as
+// "## art" or "## faq" stay part of the card text
+const languageCodes = `aa ab ae af ak am an ar as av ay az ba be bg bi bm bn bo br bs ca ce
+ch co cr cs cu cv cy da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he
+hi ho hr ht hu hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw
+ky la lb lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny oc oj
+om or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw ta
+te tg th ti tk tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu`
+
 // Parser handles parsing markdown files with YAML frontmatter and body extraction
-type Parser struct{}
+type Parser struct {
+	language  string // Requested language for localized bodies (overrides card.lang)
+	strictTCG bool   // Leave card.tcg empty instead of defaulting to mtg
+}
 
 // NewParser creates a new metadata parser
 func NewParser() *Parser {
 	return &Parser{}
 }
 
+// SetLanguage selects which localized body section ("## en", "## fr", ...) is used
+func (p *Parser) SetLanguage(language string) {
+	p.language = language
+}
+
+// SetStrictTCG stops cards without a card.tcg from defaulting to mtg, so
+// the caller can reject them
+func (p *Parser) SetStrictTCG(strict bool) {
+	p.strictTCG = strict
+}
+
 // ParseFile parses a markdown file and extracts metadata and content
 func (p *Parser) ParseFile(filePath string) (*Card, error) {
-	file, err := os.Open(filePath)
+	return p.parseFile(filePath, make(map[string]bool))
+}
+
+// parseFile parses a card, tracking the files already being parsed so related
+// cards that refer back to each other don't recurse forever
+func (p *Parser) parseFile(filePath string, parsing map[string]bool) (*Card, error) {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		parsing[abs] = true
+	}
+
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open file: %v", err)
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(strings.NewReader(normalizeText(string(data))))
 
 	// Check for YAML frontmatter (optional)
 	var frontmatterLines []string
@@ -104,27 +151,109 @@ func (p *Parser) ParseFile(filePath string) (*Card, error) {
 		}
 	}
 
+	// A body written in the frontmatter replaces the markdown body
+	if body := frontmatterBody(card.Metadata); body != "" {
+		card.Body = normalizeText(body)
+	}
+
+	// Pick the localized body section, if the card has any
+	p.selectLanguage(card)
+
 	// Parse structured data from markdown body
 	if err := p.parseBodyContent(card); err != nil {
 		return nil, fmt.Errorf("error parsing body content: %v", err)
 	}
 
+	// Also accept the nested form: card: { template: ./special.yaml, tcg: mtg }
+	if cardMap, ok := card.Metadata["card"].(map[string]interface{}); ok {
+		if card.Template == "" {
+			card.Template, _ = cardMap["template"].(string)
+		}
+		if card.TCG == "" {
+			card.TCG, _ = cardMap["tcg"].(string)
+		}
+		if card.PrintThis == 0 {
+			card.PrintThis, _ = cardMap["print_this"].(int)
+		}
+		if card.PrintTotal == 0 {
+			card.PrintTotal, _ = cardMap["print_total"].(int)
+		}
+	}
+
 	// Set defaults
 	p.setDefaults(card, filePath)
 
+	if err := validatePrintNumbers(card); err != nil {
+		return nil, err
+	}
+
+	// Parse the related card, if one is referenced
+	if err := p.resolveRelated(card, parsing); err != nil {
+		return nil, err
+	}
+
 	return card, nil
 }
 
-// parseBodyContent extracts structured data from the markdown body
-func (p *Parser) parseBodyContent(card *Card) error {
-	lines := strings.Split(card.Body, "\n")
+// frontmatterBody returns the card body given in the frontmatter as
+// card.body or card.rules, flat or nested under card:, usually as a YAML
+// block scalar. card.body wins over card.rules.
+func frontmatterBody(metadata map[string]interface{}) string {
+	cardMap, _ := metadata["card"].(map[string]interface{})
+	for _, field := range []string{"body", "rules"} {
+		if body, ok := metadata["card."+field].(string); ok && strings.TrimSpace(body) != "" {
+			return body
+		}
+		if body, ok := cardMap[field].(string); ok && strings.TrimSpace(body) != "" {
+			return body
+		}
+	}
+	return ""
+}
 
-	var rulesLines []string
-	var flavorLines []string
-	inFlavorSection := false
+// normalizeText strips a leading UTF-8 byte order mark and converts CRLF and
+// lone CR line endings to LF, so files saved on Windows or old Macs parse alike
+func normalizeText(text string) string {
+	text = strings.TrimPrefix(text, "\uFEFF")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+// resolveRelated parses the card.related file, relative to the card's own file.
+// A reference back to a card already being parsed is left unresolved.
+func (p *Parser) resolveRelated(card *Card, parsing map[string]bool) error {
+	related := card.Related
+	if related == "" {
+		// Also accept the nested form: card: { related: ./token.md }
+		if cardMap, ok := card.Metadata["card"].(map[string]interface{}); ok {
+			related, _ = cardMap["related"].(string)
+		}
+	}
+	if related == "" {
+		return nil
+	}
+
+	path := related
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(card.SourceFile), path)
+	}
+
+	if abs, err := filepath.Abs(path); err == nil && parsing[abs] {
+		return nil // Cycle: the related card is already being parsed
+	}
+
+	relatedCard, err := p.parseFile(path, parsing)
+	if err != nil {
+		return fmt.Errorf("failed to parse related card %s: %v", related, err)
+	}
+	card.RelatedCard = relatedCard
+	return nil
+}
+
+// parseBodyContent extracts structured data from the markdown body
+func (p *Parser) parseBodyContent(card *Card) error {
+	for _, raw := range strings.Split(card.Body, "\n") {
+		line := strings.TrimSpace(raw)
 
 		// Extract title from # Header (only if not set in frontmatter)
 		if card.Title == "" && strings.HasPrefix(line, "# ") {
@@ -132,8 +261,8 @@ func (p *Parser) parseBodyContent(card *Card) error {
 			continue
 		}
 
-		// Extract mana cost from > {{mtg.cost...}} blockquote
-		if strings.HasPrefix(line, "> {{") && strings.HasSuffix(line, "}}") {
+		// Extract mana cost from > {{mtg.cost...}} or > {2}{W}{W} blockquote
+		if isManaCostLine(line) {
 			if card.ManaCost == "" { // Only set if not already set
 				card.ManaCost = strings.TrimSpace(line[2:]) // Remove "> "
 			}
@@ -141,12 +270,41 @@ func (p *Parser) parseBodyContent(card *Card) error {
 		}
 
 		// Extract type from > **Type** blockquote
-		if strings.HasPrefix(line, "> **") && strings.HasSuffix(line, "**") {
+		if isTypeLine(line) {
 			if card.Type == "" { // Only set if not already set
 				// Extract text between > ** and **
 				typeText := line[4 : len(line)-2] // Remove "> **" and "**"
 				card.Type = strings.TrimSpace(typeText)
 			}
+		}
+	}
+
+	card.RulesText, card.FlavorText = SplitRulesText(card.Body)
+	return nil
+}
+
+// isManaCostLine reports whether a trimmed body line is a > {cost} blockquote
+func isManaCostLine(line string) bool {
+	return strings.HasPrefix(line, "> {") && strings.HasSuffix(line, "}")
+}
+
+// isTypeLine reports whether a trimmed body line is a > **Type** blockquote
+func isTypeLine(line string) bool {
+	return strings.HasPrefix(line, "> **") && strings.HasSuffix(line, "**")
+}
+
+// SplitRulesText extracts the rules text and flavor text from markdown card
+// content. Headers and the cost and type blockquotes are dropped; italic
+// lines after a "---" rule are flavor text.
+func SplitRulesText(body string) (rules string, flavor string) {
+	var rulesLines []string
+	var flavorLines []string
+	inFlavorSection := false
+
+	for _, raw := range strings.Split(body, "\n") {
+		line := strings.TrimSpace(raw)
+
+		if isManaCostLine(line) || isTypeLine(line) {
 			continue
 		}
 
@@ -156,8 +314,14 @@ func (p *Parser) parseBodyContent(card *Card) error {
 			continue
 		}
 
-		// Skip empty lines and headers
-		if line == "" || strings.HasPrefix(line, "#") {
+		// Keep blank lines between rules paragraphs; skip headers
+		if line == "" {
+			if !inFlavorSection {
+				rulesLines = append(rulesLines, "")
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
 			continue
 		}
 
@@ -171,16 +335,80 @@ func (p *Parser) parseBodyContent(card *Card) error {
 			continue
 		}
 
-		// Everything else is rules text
-		if line != "" {
-			rulesLines = append(rulesLines, line)
+		// Everything else is rules text, keeping its indentation
+		rulesLines = append(rulesLines, strings.TrimRight(raw, " \t\r"))
+	}
+
+	// Join the extracted content, preserving the source's line and paragraph structure
+	return strings.Trim(strings.Join(rulesLines, "\n"), "\n"), strings.Join(flavorLines, "\n")
+}
+
+// selectLanguage narrows the body to a single localized section.
+// Content before the first language header is shared by all languages.
+// The requested language wins, then card.lang, then the default, then the first section.
+func (p *Parser) selectLanguage(card *Card) {
+	// Support nested card.lang in frontmatter
+	if card.Language == "" {
+		if cardMap, ok := card.Metadata["card"].(map[string]interface{}); ok {
+			if lang, ok := cardMap["lang"].(string); ok {
+				card.Language = lang
+			}
+		}
+	}
+
+	lines := strings.Split(card.Body, "\n")
+
+	var shared []string
+	var order []string
+	sections := make(map[string][]string)
+	current := ""
+
+	for _, line := range lines {
+		if match := languageHeader.FindStringSubmatch(strings.TrimSpace(line)); match != nil && slices.Contains(strings.Fields(languageCodes), match[2]) {
+			current = strings.ToLower(strings.ReplaceAll(match[1], "_", "-"))
+			if _, exists := sections[current]; !exists {
+				order = append(order, current)
+			}
+			sections[current] = append(sections[current], "")
+			continue
+		}
+
+		if current == "" {
+			shared = append(shared, line)
+		} else {
+			sections[current] = append(sections[current], line)
+		}
+	}
+
+	if len(order) == 0 {
+		return // Not a localized card
+	}
+
+	selected := order[0]
+	for _, candidate := range []string{p.language, card.Language, defaultLanguage} {
+		candidate = strings.ToLower(strings.ReplaceAll(candidate, "_", "-"))
+		if _, exists := sections[candidate]; candidate != "" && exists {
+			selected = candidate
+			break
 		}
 	}
 
-	// Join the extracted content
-	card.RulesText = strings.Join(rulesLines, "\n\n")
-	card.FlavorText = strings.Join(flavorLines, "\n")
+	card.Language = selected
+	card.Body = strings.Join(append(shared, sections[selected]...), "\n")
+}
 
+// validatePrintNumbers rejects negative print numbers and a card.print_this
+// past card.print_total, which would render as e.g. "5/3"
+func validatePrintNumbers(card *Card) error {
+	if card.PrintThis < 0 {
+		return fmt.Errorf("card.print_this must not be negative, got %d", card.PrintThis)
+	}
+	if card.PrintTotal < 0 {
+		return fmt.Errorf("card.print_total must not be negative, got %d", card.PrintTotal)
+	}
+	if card.PrintThis > card.PrintTotal {
+		return fmt.Errorf("card.print_this %d is greater than card.print_total %d", card.PrintThis, card.PrintTotal)
+	}
 	return nil
 }
 
@@ -198,12 +426,12 @@ func (p *Parser) setDefaults(card *Card, filePath string) {
 		card.Title = titleText
 	}
 
-	// Default print info
+	// Default print info; an unset total is at least the card's own number
 	if card.PrintThis == 0 {
 		card.PrintThis = 1
 	}
 	if card.PrintTotal == 0 {
-		card.PrintTotal = 1
+		card.PrintTotal = max(card.PrintThis, 1)
 	}
 
 	// Default rarity
@@ -221,13 +449,9 @@ func (p *Parser) setDefaults(card *Card, filePath string) {
 		card.Artist = "Unknown Artist"
 	}
 
-	// Default TCG
-	if card.TCG == "" {
+	// Default TCG, unless the caller rejects cards without one. A card with
+	// its own template file takes the template's TCG instead.
+	if card.TCG == "" && card.Template == "" && !p.strictTCG {
 		card.TCG = "mtg" // Default to MTG for now
 	}
-
-	// Default CardStyle
-	if card.CardStyle == "" {
-		card.CardStyle = "basic" // Default to basic cardstyle
-	}
 }
@@ -0,0 +1,209 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// parseCard writes frontmatter to a card file and parses it
+func parseCard(t *testing.T, frontmatter string) (*Card, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "card.md")
+	content := "---\ncard.tcg: mtg\ncard.title: \"Test\"\n" + frontmatter + "---\n# Test\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write card: %v", err)
+	}
+	return NewParser().ParseFile(path)
+}
+
+func TestPrintNumbers(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter string
+		this, total int
+	}{
+		{"unset", "", 1, 1},
+		{"zero", "card.print_this: 0\ncard.print_total: 0\n", 1, 1},
+		{"total unset", "card.print_this: 37\n", 37, 37},
+		{"in order", "card.print_this: 3\ncard.print_total: 5\n", 3, 5},
+		{"nested", "card:\n  print_this: 2\n  print_total: 9\n", 2, 9},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			card, err := parseCard(t, test.frontmatter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if card.PrintThis != test.this || card.PrintTotal != test.total {
+				t.Errorf("got %d/%d, want %d/%d", card.PrintThis, card.PrintTotal, test.this, test.total)
+			}
+		})
+	}
+}
+
+func TestPrintNumbersInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter string
+		want        string
+	}{
+		{"negative this", "card.print_this: -2\n", "card.print_this must not be negative"},
+		{"negative total", "card.print_total: -5\n", "card.print_total must not be negative"},
+		{"this past total", "card.print_this: 5\ncard.print_total: 3\n", "card.print_this 5 is greater than card.print_total 3"},
+		{"nested this past total", "card:\n  print_this: 5\n  print_total: 3\n", "greater than card.print_total"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseCard(t, test.frontmatter)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLineEndings(t *testing.T) {
+	lines := []string{
+		"---",
+		"card.tcg: mtg",
+		"card.set: \"ABC\"",
+		"---",
+		"# Shock",
+		"> {R}",
+		"> **Instant**",
+		"",
+		"Shock deals 2 damage to any target.",
+		"",
+		"---",
+		"*Zap.*",
+	}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		newline string
+	}{
+		{"LF", "", "\n"},
+		{"CRLF", "", "\r\n"},
+		{"CR", "", "\r"},
+		{"BOM and CRLF", "\uFEFF", "\r\n"},
+		{"BOM and LF", "\uFEFF", "\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "shock.md")
+			content := test.prefix + strings.Join(lines, test.newline) + test.newline
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write card: %v", err)
+			}
+
+			card, err := NewParser().ParseFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if card.Set != "ABC" {
+				t.Errorf("frontmatter not read: got set %q", card.Set)
+			}
+			if card.Title != "Shock" || card.ManaCost != "{R}" || card.Type != "Instant" {
+				t.Errorf("got title %q, cost %q, type %q", card.Title, card.ManaCost, card.Type)
+			}
+			if card.RulesText != "Shock deals 2 damage to any target." || card.FlavorText != "Zap." {
+				t.Errorf("got rules %q and flavor %q", card.RulesText, card.FlavorText)
+			}
+			if strings.Contains(card.Body, "\r") {
+				t.Errorf("body still contains carriage returns: %q", card.Body)
+			}
+		})
+	}
+}
+
+func TestFrontmatterBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter string
+		rules       string
+		flavor      string
+	}{
+		{
+			"literal card.body",
+			"card.body: |\n  > **Instant**\n\n  Draw a card.\n\n  ---\n  *Knowledge.*\n",
+			"Draw a card.", "Knowledge.",
+		},
+		{
+			"folded card.rules",
+			"card.rules: >\n  Draw a card,\n  then discard a card.\n",
+			"Draw a card, then discard a card.", "",
+		},
+		{
+			"nested body",
+			"card:\n  body: |\n    Draw a card.\n",
+			"Draw a card.", "",
+		},
+		{
+			"body wins over rules",
+			"card.rules: \"Ignored.\"\ncard.body: \"Draw a card.\"\n",
+			"Draw a card.", "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "card.md")
+			content := "---\ncard.tcg: mtg\n" + test.frontmatter + "---\n# Markdown Title\n\nMarkdown rules.\n"
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write card: %v", err)
+			}
+
+			card, err := NewParser().ParseFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if card.RulesText != test.rules || card.FlavorText != test.flavor {
+				t.Errorf("got rules %q and flavor %q, want %q and %q", card.RulesText, card.FlavorText, test.rules, test.flavor)
+			}
+		})
+	}
+}
+
+func TestLanguageSections(t *testing.T) {
+	body := "# Bolt\n\nShared.\n\n## en\nDeals 3 damage.\n\n## art\nBy Jane.\n\n## fr\nInflige 3 blessures.\n\n## faq\nNone."
+	path := filepath.Join(t.TempDir(), "card.md")
+	if err := os.WriteFile(path, []byte("---\ncard.tcg: mtg\ncard.title: Bolt\n---\n"+body), 0644); err != nil {
+		t.Fatalf("failed to write card: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		want     []string
+		dropped  []string
+	}{
+		{"en", []string{"Shared.", "Deals 3 damage.", "## art", "By Jane."}, []string{"blessures", "## faq"}},
+		{"fr", []string{"Shared.", "Inflige 3 blessures.", "## faq", "None."}, []string{"Deals", "## art"}},
+	}
+
+	for _, test := range tests {
+		parser := NewParser()
+		parser.SetLanguage(test.language)
+		card, err := parser.ParseFile(path)
+		if err != nil {
+			t.Fatalf("failed to parse: %v", err)
+		}
+		// Headings that aren't language codes belong to the section they're in
+		for _, want := range test.want {
+			if !strings.Contains(card.Body, want) {
+				t.Errorf("%s body %q is missing %q", test.language, card.Body, want)
+			}
+		}
+		for _, dropped := range test.dropped {
+			if strings.Contains(card.Body, dropped) {
+				t.Errorf("%s body %q contains %q from another language", test.language, card.Body, dropped)
+			}
+		}
+	}
+}
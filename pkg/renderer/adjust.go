@@ -0,0 +1,68 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// ToneAdjustment holds per-layer image adjustments; 1.0 leaves a channel unchanged
+type ToneAdjustment struct {
+	Brightness float64
+	Contrast   float64
+	Saturation float64
+}
+
+// valueOr dereferences an optional setting, using def when it's unset
+func valueOr(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// AdjustImage applies brightness, contrast and saturation to a decoded image.
+// Results are cached by source path and parameters.
+func (ip *ImageProcessor) AdjustImage(path string, img image.Image, adjust ToneAdjustment) image.Image {
+	if adjust == (ToneAdjustment{1, 1, 1}) {
+		return img
+	}
+
+	key := fmt.Sprintf("%s|%g|%g|%g", path, adjust.Brightness, adjust.Contrast, adjust.Saturation)
+	if cached, exists := ip.adjusted[key]; exists {
+		return cached
+	}
+
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+	draw.Draw(out, bounds, img, bounds.Min, draw.Src)
+
+	for i := 0; i < len(out.Pix); i += 4 {
+		// Brightness scales, contrast stretches around mid-gray
+		var px [3]float64
+		for c := range px {
+			v := float64(out.Pix[i+c]) / 255 * adjust.Brightness
+			px[c] = (v-0.5)*adjust.Contrast + 0.5
+		}
+
+		// Saturation blends each channel with the pixel's luminance
+		lum := 0.299*px[0] + 0.587*px[1] + 0.114*px[2]
+		for c := range px {
+			out.Pix[i+c] = clampChannel(lum + (px[c]-lum)*adjust.Saturation)
+		}
+	}
+
+	ip.adjusted[key] = out
+	return out
+}
+
+// clampChannel converts a 0..1 channel value to a byte, clamping out-of-range values
+func clampChannel(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
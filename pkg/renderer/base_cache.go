@@ -0,0 +1,149 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// baseImage is a pre-rendered background shared by every card of a template
+type baseImage struct {
+	img     image.Image
+	layers  int           // Number of leading layers already drawn into img
+	results []LayerResult // What each of those layers drew
+}
+
+// baseFor returns the cached background for a template, rendering it on first use.
+// Only the leading run of static layers (no {{variables}}) is cached so layer
+// order is preserved; everything after the first dynamic layer renders per card.
+func (r *Renderer) baseFor(template *templates.Template, vars map[string]string) (*baseImage, error) {
+	background := r.variableProcessor.SubstituteVariables(template.Background, vars)
+
+	// Card-level fit overrides apply to every image layer, so they're part of the key
+	key := fmt.Sprintf("%p|%s|%s", template, vars["card.artwork.fit"], background)
+	if base, exists := r.baseCache[key]; exists {
+		return base, nil
+	}
+
+	dc := gg.NewContext(template.Dimensions.Width, template.Dimensions.Height)
+
+	if err := r.paintBackground(dc, background); err != nil {
+		return nil, err
+	}
+
+	var results []LayerResult
+	for _, layer := range template.Layers {
+		if !isStaticLayer(layer, template) {
+			break
+		}
+		result, err := r.renderLayer(dc, layer, vars, template)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering layer '%s': %v", layer.Name, err)
+		}
+		results = append(results, result)
+	}
+
+	base := &baseImage{img: dc.Image(), layers: len(results), results: results}
+	r.baseCache[key] = base
+	return base, nil
+}
+
+// paintBackground fills the canvas with the template's background: white by
+// default, fully transparent, any color ParseColor accepts, or an image
+// stretched to the card
+func (r *Renderer) paintBackground(dc *gg.Context, background string) error {
+	switch strings.ToLower(strings.TrimSpace(background)) {
+	case "":
+		r.fillCanvas(dc, color.White)
+		return nil
+	case "transparent", "none":
+		return nil // New contexts start fully transparent
+	}
+
+	// Anything that isn't a color is an image path, except a mistyped hex color
+	fill, colorErr := r.utils.ParseColor(background)
+	if colorErr == nil {
+		r.fillCanvas(dc, fill)
+		return nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(background), "#") {
+		return fmt.Errorf("invalid background color: %v", colorErr)
+	}
+
+	img, err := r.imageProcessor.LoadImage(background)
+	if err != nil {
+		return fmt.Errorf("failed to load background image: %v", err)
+	}
+	region := templates.Region{Width: dc.Width(), Height: dc.Height()}
+	stretched := r.imageProcessor.CreateFittedImage(img, region, "stretch")
+	if r.svg != nil {
+		return r.svg.image(stretched, 0, 0)
+	}
+	dc.DrawImage(stretched, 0, 0)
+	return nil
+}
+
+// fillCanvas paints the whole card in one color, or records it while rendering SVG
+func (r *Renderer) fillCanvas(dc *gg.Context, fill color.Color) {
+	if r.svg != nil {
+		r.svg.rect(0, 0, float64(dc.Width()), float64(dc.Height()), fill, nil, 0)
+		return
+	}
+	dc.SetColor(fill)
+	dc.Clear()
+}
+
+// isStaticLayer reports whether a layer renders identically for every card
+func isStaticLayer(layer templates.Layer, template *templates.Template) bool {
+	if layer.Variant != "" {
+		return false // Selected per card by variant_field
+	}
+	if layer.OverflowInto != "" || isOverflowTarget(layer, template) {
+		return false // Spilled text depends on each card's content
+	}
+	if layer.ContentField != "" {
+		return false // Content is selected per card by content_field
+	}
+
+	for _, field := range layerInputs(layer, template) {
+		if strings.Contains(field, "{{") {
+			return false
+		}
+	}
+	return true
+}
+
+// layerInputs returns the layer fields that may reference variables, so
+// what a layer draws is fixed by their resolved values
+func layerInputs(layer templates.Layer, template *templates.Template) []string {
+	fields := []string{layer.Source, layer.Fallback, layer.Content, layer.Condition,
+		layer.Count, layer.Max, layer.Color, layer.EmptyColor, layer.SourceSheet, layer.SheetIndex, layer.TrimColor}
+	fields = append(fields, layer.Sources...)
+	if layer.Type == "text" {
+		// Include fields inherited from the template's default font
+		font := template.LayerFont(layer)
+		fields = append(fields, font.Family, font.Color, font.FillImage)
+		if font.FillGradient != nil {
+			fields = append(fields, font.FillGradient.Colors...)
+		}
+		if size, ok := font.Size.(string); ok {
+			fields = append(fields, size)
+		}
+	}
+	return fields
+}
+
+// isOverflowTarget reports whether another layer overflows into this one
+func isOverflowTarget(layer templates.Layer, template *templates.Template) bool {
+	for _, other := range template.Layers {
+		if other.OverflowInto == layer.Name {
+			return true
+		}
+	}
+	return false
+}
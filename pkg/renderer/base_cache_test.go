@@ -0,0 +1,76 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+func TestPaintBackground(t *testing.T) {
+	paper := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for i := 0; i < len(paper.Pix); i += 4 {
+		paper.Pix[i], paper.Pix[i+1], paper.Pix[i+2], paper.Pix[i+3] = 10, 20, 30, 255
+	}
+	paperPath := filepath.Join(t.TempDir(), "paper.png")
+	file, err := os.Create(paperPath)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := png.Encode(file, paper); err != nil {
+		t.Fatalf("failed to encode image: %v", err)
+	}
+	file.Close()
+
+	tests := []struct {
+		background string
+		want       color.NRGBA
+	}{
+		{"", color.NRGBA{255, 255, 255, 255}},
+		{"transparent", color.NRGBA{}},
+		{"#336699", color.NRGBA{0x33, 0x66, 0x99, 255}},
+		{" Navy ", color.NRGBA{0, 0, 128, 255}},
+		{"rgba(34, 40, 49, 1)", color.NRGBA{34, 40, 49, 255}},
+		{paperPath, color.NRGBA{10, 20, 30, 255}}, // Not a color, so an image
+	}
+
+	r := NewRenderer()
+	for _, test := range tests {
+		dc := gg.NewContext(4, 4)
+		if err := r.paintBackground(dc, test.background); err != nil {
+			t.Errorf("background %q: %v", test.background, err)
+			continue
+		}
+		if got := color.NRGBAModel.Convert(dc.Image().At(2, 2)); got != test.want {
+			t.Errorf("background %q painted %v, want %v", test.background, got, test.want)
+		}
+	}
+
+	// A whole card uses an rgb() paper color
+	template := &templates.Template{
+		Name:       "paper",
+		Dimensions: templates.Dimensions{Width: 20, Height: 20},
+		Background: "rgb(34, 40, 49)",
+	}
+	card := &metadata.Card{TCG: "mtg", PrintThis: 1, PrintTotal: 1}
+	img, err := r.RenderImage(card, template)
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if got := color.NRGBAModel.Convert(img.At(10, 10)); got != (color.NRGBA{34, 40, 49, 255}) {
+		t.Errorf("rgb() background rendered as %v, want rgb(34, 40, 49)", got)
+	}
+
+	// A mistyped hex color is reported as a color, not a missing image
+	if err := r.paintBackground(gg.NewContext(4, 4), "#33669"); err == nil || !strings.Contains(err.Error(), "invalid background color") {
+		t.Errorf("got error %v, want an invalid background color error", err)
+	}
+}
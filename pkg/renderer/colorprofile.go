@@ -0,0 +1,65 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+)
+
+// pngHeaderLength covers the PNG signature and the IHDR chunk, which must come
+// first; color space chunks are inserted right after it
+const pngHeaderLength = 8 + 4 + 4 + 13 + 4
+
+// SetEmbedSRGB tags saved card PNGs as sRGB so print and color-managed viewers
+// don't reinterpret their colors
+func (r *Renderer) SetEmbedSRGB(enabled bool) {
+	r.embedSRGB = enabled
+}
+
+// encodeSRGBPNG writes img as a PNG carrying the sRGB color space chunk, plus the
+// gAMA and cHRM equivalents the PNG spec recommends for older decoders
+func encodeSRGBPNG(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	encoded := buf.Bytes()
+	if len(encoded) < pngHeaderLength {
+		return fmt.Errorf("encoded PNG is truncated")
+	}
+
+	var out bytes.Buffer
+	out.Write(encoded[:pngHeaderLength])
+	writePNGChunk(&out, "sRGB", []byte{0}) // Perceptual rendering intent
+	writePNGChunk(&out, "gAMA", pngUint32s(45455))
+	// White point and red, green, blue primaries (x, y pairs) scaled by 100000
+	writePNGChunk(&out, "cHRM", pngUint32s(31270, 32900, 64000, 33000, 30000, 60000, 15000, 6000))
+	out.Write(encoded[pngHeaderLength:])
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// writePNGChunk appends a length-prefixed, CRC-terminated PNG chunk
+func writePNGChunk(out *bytes.Buffer, chunkType string, data []byte) {
+	binary.Write(out, binary.BigEndian, uint32(len(data)))
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(chunkType))
+	crc.Write(data)
+	out.WriteString(chunkType)
+	out.Write(data)
+	binary.Write(out, binary.BigEndian, crc.Sum32())
+}
+
+// pngUint32s encodes values as consecutive big-endian integers
+func pngUint32s(values ...uint32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.BigEndian.PutUint32(data[4*i:], value)
+	}
+	return data
+}
@@ -0,0 +1,77 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// diffMarkColor highlights pixels that differ in a comparison diff image
+var diffMarkColor = color.RGBA{255, 0, 0, 255}
+
+// Comparison is the result of comparing a rendered card against a reference
+type Comparison struct {
+	DiffPixels  int         // Pixels differing by more than the tolerance
+	TotalPixels int         // Pixels compared
+	Diff        *image.RGBA // Faded reference with differing pixels marked in red
+}
+
+// Percent returns the share of differing pixels, 0-100
+func (c Comparison) Percent() float64 {
+	if c.TotalPixels == 0 {
+		return 0
+	}
+	return 100 * float64(c.DiffPixels) / float64(c.TotalPixels)
+}
+
+// Matches reports whether no pixel differs beyond the tolerance
+func (c Comparison) Matches() bool {
+	return c.DiffPixels == 0
+}
+
+// Compare compares two images pixel by pixel. A pixel differs when any channel
+// (including alpha) is off by more than tolerance, on a 0-255 scale, which
+// absorbs small antialiasing differences between platforms.
+func Compare(rendered, reference image.Image, tolerance uint8) (Comparison, error) {
+	bounds := reference.Bounds()
+	if rendered.Bounds().Dx() != bounds.Dx() || rendered.Bounds().Dy() != bounds.Dy() {
+		return Comparison{}, fmt.Errorf("image sizes differ: %dx%d rendered, %dx%d reference",
+			rendered.Bounds().Dx(), rendered.Bounds().Dy(), bounds.Dx(), bounds.Dy())
+	}
+
+	offset := rendered.Bounds().Min.Sub(bounds.Min)
+	result := Comparison{
+		TotalPixels: bounds.Dx() * bounds.Dy(),
+		Diff:        image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy())),
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			want := color.NRGBAModel.Convert(reference.At(x, y)).(color.NRGBA)
+			got := color.NRGBAModel.Convert(rendered.At(x+offset.X, y+offset.Y)).(color.NRGBA)
+
+			diffX, diffY := x-bounds.Min.X, y-bounds.Min.Y
+			if channelDiffers(got.R, want.R, tolerance) || channelDiffers(got.G, want.G, tolerance) ||
+				channelDiffers(got.B, want.B, tolerance) || channelDiffers(got.A, want.A, tolerance) {
+				result.DiffPixels++
+				result.Diff.Set(diffX, diffY, diffMarkColor)
+				continue
+			}
+
+			// Matching pixels are faded so the differences stand out
+			gray := uint8((uint16(want.R) + uint16(want.G) + uint16(want.B)) / 3)
+			faded := 255 - (255-gray)/4
+			result.Diff.Set(diffX, diffY, color.RGBA{faded, faded, faded, 255})
+		}
+	}
+
+	return result, nil
+}
+
+// channelDiffers reports whether two channel values are further apart than tolerance
+func channelDiffers(a, b, tolerance uint8) bool {
+	if a > b {
+		return a-b > tolerance
+	}
+	return b-a > tolerance
+}
@@ -0,0 +1,106 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/fogleman/gg"
+)
+
+// Contact sheet layout constants
+const (
+	contactThumbWidth   = 250
+	contactPadding      = 20
+	contactLabelHeight  = 28
+	contactLabelSize    = 14
+	contactDefaultCols  = 5
+	contactLabelMaxRune = 32
+)
+
+// ContactSheetItem is a rendered card to include in a contact sheet
+type ContactSheetItem struct {
+	ImagePath string
+	Label     string
+}
+
+// RenderContactSheet tiles thumbnails of rendered cards into a single PNG grid
+func (r *Renderer) RenderContactSheet(items []ContactSheetItem, columns int, outputPath string) error {
+	if len(items) == 0 {
+		return fmt.Errorf("no cards to include in contact sheet")
+	}
+	if columns <= 0 {
+		columns = contactDefaultCols
+	}
+	if columns > len(items) {
+		columns = len(items)
+	}
+
+	// Load and downscale every card, tracking the tallest thumbnail for row height
+	thumbs := make([]image.Image, len(items))
+	thumbHeight := 0
+	for i, item := range items {
+		img, err := gg.LoadImage(item.ImagePath)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", item.ImagePath, err)
+		}
+
+		thumbs[i] = r.scaleToWidth(img, contactThumbWidth)
+		if h := thumbs[i].Bounds().Dy(); h > thumbHeight {
+			thumbHeight = h
+		}
+	}
+
+	rows := (len(items) + columns - 1) / columns
+	cellWidth := contactThumbWidth + contactPadding
+	cellHeight := thumbHeight + contactLabelHeight + contactPadding
+
+	dc := gg.NewContext(columns*cellWidth+contactPadding, rows*cellHeight+contactPadding)
+	dc.SetColor(color.White)
+	dc.Clear()
+
+	for i, thumb := range thumbs {
+		x := contactPadding + (i%columns)*cellWidth
+		y := contactPadding + (i/columns)*cellHeight
+
+		dc.DrawImage(thumb, x, y)
+
+		// Thin border so white cards stay distinguishable
+		dc.SetColor(color.RGBA{180, 180, 180, 255})
+		dc.SetLineWidth(1)
+		dc.DrawRectangle(float64(x), float64(y), float64(thumb.Bounds().Dx()), float64(thumb.Bounds().Dy()))
+		dc.Stroke()
+
+		// Label under the thumbnail
+		r.textProcessor.setFont(dc, "", contactLabelSize, false, false, color.Black)
+		label := truncateLabel(items[i].Label, contactLabelMaxRune)
+		dc.DrawStringAnchored(label, float64(x+contactThumbWidth/2), float64(y+thumbHeight+contactLabelHeight/2), 0.5, 0.5)
+	}
+
+	if err := dc.SavePNG(outputPath); err != nil {
+		return fmt.Errorf("error saving contact sheet to %s: %v", outputPath, err)
+	}
+
+	return nil
+}
+
+// scaleToWidth downscales an image to the given width, preserving aspect ratio
+func (r *Renderer) scaleToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	scale := float64(width) / float64(bounds.Dx())
+	height := int(float64(bounds.Dy()) * scale)
+
+	dc := gg.NewContext(width, height)
+	dc.Scale(scale, scale)
+	dc.DrawImage(img, 0, 0)
+	return dc.Image()
+}
+
+// truncateLabel shortens a label to at most max runes, adding an ellipsis
+func truncateLabel(label string, max int) string {
+	runes := []rune(label)
+	if len(runes) <= max {
+		return label
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,53 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// Crop mark colors: black registration lines on white stock
+var (
+	cropMarkColor  = color.Black
+	cropMarkMargin = color.White
+)
+
+// AddCropMarks returns the card centered on a canvas extended by the bleed on
+// every side, with short marks in each corner continuing the trim lines. The
+// marks stay in the bleed and never touch the card itself.
+func (r *Renderer) AddCropMarks(img image.Image, template *templates.Template) image.Image {
+	bleed, _ := printMargins(template.Dimensions)
+	bleed = int(math.Round(float64(bleed) * r.scale))
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+
+	dc := gg.NewContext(width+2*bleed, height+2*bleed)
+	dc.SetColor(cropMarkMargin)
+	dc.Clear()
+	dc.DrawImage(img, bleed, bleed)
+
+	// Marks end a quarter of the bleed short of the card so a slightly
+	// off cut doesn't leave ink on the trimmed edge
+	gap := float64(bleed) / 4
+	left, top := float64(bleed), float64(bleed)
+	right, bottom := float64(bleed+width), float64(bleed+height)
+	outerX, outerY := float64(width+2*bleed), float64(height+2*bleed)
+
+	dc.SetColor(cropMarkColor)
+	dc.SetLineWidth(math.Max(1, r.scale))
+	for _, x := range []float64{left, right} {
+		dc.DrawLine(x, 0, x, top-gap)
+		dc.DrawLine(x, bottom+gap, x, outerY)
+	}
+	for _, y := range []float64{top, bottom} {
+		dc.DrawLine(0, y, left-gap, y)
+		dc.DrawLine(right+gap, y, outerX, y)
+	}
+	dc.Stroke()
+
+	return dc.Image()
+}
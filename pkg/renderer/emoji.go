@@ -0,0 +1,106 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// SetEmojiFont parses TTF data used for any character the text's own font
+// has no glyph for, such as emoji. Only outline (monochrome) fonts can be
+// drawn; color bitmap emoji fonts fail to parse.
+func (fr *FontRegistry) SetEmojiFont(data []byte) error {
+	f, err := truetype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse emoji font: %v", err)
+	}
+	fr.emoji = f
+	return nil
+}
+
+// LoadEmojiFont loads the font drawn for emoji and other characters missing
+// from a layer's font
+func (r *Renderer) LoadEmojiFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read emoji font %s: %v", path, err)
+	}
+	return r.fonts.SetEmojiFont(data)
+}
+
+// fallbackFace draws characters missing from the primary font with a
+// fallback face, so both drawing and measuring use the glyph actually drawn
+type fallbackFace struct {
+	font.Face
+	fallback   font.Face
+	inPrimary  func(rune) bool // Whether the primary font has a glyph for a character
+	inFallback func(rune) bool
+}
+
+// hasGlyph reports whether a font has a glyph for a character rather than
+// drawing its missing glyph box
+func hasGlyph(f *truetype.Font) func(rune) bool {
+	return func(r rune) bool {
+		return f.Index(r) != 0
+	}
+}
+
+// usesFallback reports whether r is drawn by the fallback face
+func (f *fallbackFace) usesFallback(r rune) bool {
+	return !f.inPrimary(r) && f.inFallback(r)
+}
+
+// invisible reports whether r is an emoji joiner or variation selector that
+// neither font draws, which would otherwise show as a box between emoji
+func (f *fallbackFace) invisible(r rune) bool {
+	switch r {
+	case '\u200d', '\ufe0e', '\ufe0f': // Zero width joiner, text and emoji presentation
+		return !f.inPrimary(r) && !f.inFallback(r)
+	}
+	return false
+}
+
+// Glyph draws r from whichever face has it
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	if f.invisible(r) {
+		return image.Rectangle{}, image.NewAlpha(image.Rectangle{}), image.Point{}, 0, true
+	}
+	if f.usesFallback(r) {
+		return f.fallback.Glyph(dot, r)
+	}
+	return f.Face.Glyph(dot, r)
+}
+
+// GlyphBounds returns the bounds of r in whichever face has it
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	if f.invisible(r) {
+		return fixed.Rectangle26_6{}, 0, true
+	}
+	if f.usesFallback(r) {
+		return f.fallback.GlyphBounds(r)
+	}
+	return f.Face.GlyphBounds(r)
+}
+
+// GlyphAdvance returns the advance of r in whichever face has it
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	if f.invisible(r) {
+		return 0, true
+	}
+	if f.usesFallback(r) {
+		return f.fallback.GlyphAdvance(r)
+	}
+	return f.Face.GlyphAdvance(r)
+}
+
+// Kern only kerns pairs drawn from the primary face
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if f.usesFallback(r0) || f.usesFallback(r1) {
+		return 0
+	}
+	return f.Face.Kern(r0, r1)
+}
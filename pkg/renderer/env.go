@@ -0,0 +1,25 @@
+package renderer
+
+import "os"
+
+// SetExpandEnv enables ${VAR}/$VAR expansion in image layer sources
+func (r *Renderer) SetExpandEnv(enabled bool) {
+	r.expandEnv = enabled
+}
+
+// expandEnvironment expands environment variable references in an image source
+// when enabled. Undefined variables expand to empty and are warned about once.
+func (r *Renderer) expandEnvironment(source string) string {
+	if !r.expandEnv {
+		return source
+	}
+
+	return os.Expand(source, func(name string) string {
+		value, exists := os.LookupEnv(name)
+		if !exists && !r.missingEnv[name] {
+			r.missingEnv[name] = true
+			r.log.Warnf("environment variable %s is not set (used in image source %s)", name, source)
+		}
+		return value
+	})
+}
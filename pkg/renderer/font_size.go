@@ -0,0 +1,50 @@
+package renderer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// layerFont returns a text layer's font with relative sizes resolved to points:
+// "10%" is a share of the region height, "+4" or "-2" is relative to the
+// template's default font size. Other sizes are left for resolveFontSize.
+// With a render scale, sizes in points are multiplied by it; percentages
+// already follow the scaled region.
+func (r *Renderer) layerFont(layer templates.Layer, template *templates.Template, vars map[string]string) *templates.Font {
+	font := template.LayerFont(layer)
+	if layer.Raw && (layer.Font == nil || layer.Font.Family == "") {
+		font.Family = MonoFamily // Raw text is monospace unless the layer picks a family
+	}
+	size, ok := font.Size.(string)
+	if !ok {
+		return r.scaleFont(font, vars)
+	}
+
+	resolved := strings.TrimSpace(r.variableProcessor.SubstituteVariables(size, vars))
+	switch {
+	case strings.HasSuffix(resolved, "%"):
+		if percent, err := strconv.ParseFloat(strings.TrimSuffix(resolved, "%"), 64); err == nil && percent > 0 {
+			font.Size = float64(layer.Region.Height) * percent / 100
+			return font
+		}
+	case strings.HasPrefix(resolved, "+"), strings.HasPrefix(resolved, "-"):
+		if delta, err := strconv.ParseFloat(resolved, 64); err == nil {
+			// An empty layer picks up just the default_font (or 12pt) size
+			base := r.textProcessor.resolveFontSize(template.LayerFont(templates.Layer{}), vars)
+			if base+delta > 0 {
+				font.Size = base + delta
+			}
+		}
+	}
+	return r.scaleFont(font, vars)
+}
+
+// scaleFont resolves a font's size in points multiplied by the render scale
+func (r *Renderer) scaleFont(font *templates.Font, vars map[string]string) *templates.Font {
+	if r.scale != 1 {
+		font.Size = r.textProcessor.resolveFontSize(font, vars) * r.scale
+	}
+	return font
+}
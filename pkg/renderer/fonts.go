@@ -0,0 +1,394 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontStyle identifies a face variant within a font family
+type FontStyle int
+
+const (
+	FontRegular FontStyle = iota
+	FontBold
+	FontItalic
+	FontBoldItalic
+)
+
+// String returns the style's display name
+func (s FontStyle) String() string {
+	switch s {
+	case FontBold:
+		return "Bold"
+	case FontItalic:
+		return "Italic"
+	case FontBoldItalic:
+		return "Bold Italic"
+	default:
+		return "Regular"
+	}
+}
+
+// FontFace describes one registered face and where it was loaded from
+type FontFace struct {
+	Family string
+	Style  FontStyle
+	Source string // Font file path, or "registered" for fonts added via Register
+}
+
+// MonoFamily names the built-in Go Mono family, used by raw text layers
+// unless a font with this family name is registered
+const MonoFamily = "mono"
+
+// builtinFace identifies one of the embedded Go fonts
+type builtinFace struct {
+	mono  bool
+	style FontStyle
+}
+
+// syntheticSlant is the horizontal shear applied to synthesized italics
+const syntheticSlant = 0.2
+
+// FontRegistry holds the font families available to text layers
+type FontRegistry struct {
+	families map[string]map[FontStyle]*truetype.Font
+	sources  map[string]map[FontStyle]string
+	names    map[string]string // Family name as first registered, by lookup key
+	builtin  map[builtinFace]*truetype.Font
+	data     map[*truetype.Font][]byte // Font file contents, for embedding in SVG output
+	emoji    *truetype.Font            // Drawn for characters a font lacks (nil when unset)
+}
+
+// NewFontRegistry creates a registry that falls back to the Go fonts
+func NewFontRegistry() *FontRegistry {
+	return &FontRegistry{
+		families: make(map[string]map[FontStyle]*truetype.Font),
+		sources:  make(map[string]map[FontStyle]string),
+		names:    make(map[string]string),
+		builtin:  make(map[builtinFace]*truetype.Font),
+		data:     make(map[*truetype.Font][]byte),
+	}
+}
+
+// Register parses TTF/OTF data and registers it under the given family name.
+// A style suffix such as "-Bold" or "-Italic" selects the face variant.
+func (fr *FontRegistry) Register(name string, data []byte) error {
+	f, err := truetype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse font %s: %v", name, err)
+	}
+
+	family, style := splitFontStyle(name)
+	fr.data[f] = data
+	fr.add(family, style, f, "registered")
+	return nil
+}
+
+// LoadDirectory registers every TTF/OTF file found under dir.
+// A missing directory is not an error.
+func (fr *FontRegistry) LoadDirectory(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if info.IsDir() || (ext != ".ttf" && ext != ".otf") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read font %s: %v", path, err)
+		}
+
+		f, err := truetype.Parse(data)
+		if err != nil {
+			// Skip fonts we can't parse (e.g. CFF-based OTF) instead of failing the scan
+			return nil
+		}
+
+		// Prefer the family name embedded in the font, fall back to the filename
+		stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		family, style := splitFontStyle(stem)
+		if embedded := f.Name(truetype.NameIDFontFamily); embedded != "" {
+			family = embedded
+			if subfamily := f.Name(truetype.NameIDFontSubfamily); subfamily != "" {
+				style = parseFontStyle(subfamily)
+			}
+		}
+
+		fr.data[f] = data
+		fr.add(family, style, f, path)
+		return nil
+	})
+}
+
+// Faces returns every registered face sorted by family and style. Families
+// loaded later replace earlier faces of the same style, so each face is listed
+// with the source it actually resolves to.
+func (fr *FontRegistry) Faces() []FontFace {
+	var faces []FontFace
+	for key, styles := range fr.families {
+		for style := range styles {
+			faces = append(faces, FontFace{
+				Family: fr.names[key],
+				Style:  style,
+				Source: fr.sources[key][style],
+			})
+		}
+	}
+
+	sort.Slice(faces, func(i, j int) bool {
+		if a, b := strings.ToLower(faces[i].Family), strings.ToLower(faces[j].Family); a != b {
+			return a < b
+		}
+		return faces[i].Style < faces[j].Style
+	})
+	return faces
+}
+
+// Face returns a font face for a family, size and style.
+// Missing italic variants are synthesized by slanting the upright face, and
+// characters the font lacks come from the emoji font when one is set.
+func (fr *FontRegistry) Face(family string, size float64, bold, italic bool) font.Face {
+	f, synthItalic := fr.Font(family, bold, italic)
+	options := &truetype.Options{
+		Size: size,
+		DPI:  72,
+	}
+
+	face := truetype.NewFace(f, options)
+	if synthItalic {
+		face = &obliqueFace{Face: face, slant: syntheticSlant}
+	}
+	if fr.emoji != nil && fr.emoji != f {
+		face = &fallbackFace{
+			Face:       face,
+			fallback:   truetype.NewFace(fr.emoji, options),
+			inPrimary:  hasGlyph(f),
+			inFallback: hasGlyph(fr.emoji),
+		}
+	}
+	return face
+}
+
+// Font returns the best matching font for a family and style, and whether
+// italics must be synthesized because no italic variant is available.
+// Unknown families resolve to the Go fonts, and "mono" to Go Mono.
+func (fr *FontRegistry) Font(family string, bold, italic bool) (*truetype.Font, bool) {
+	style := styleFor(bold, italic)
+	key := fr.familyKey(family)
+
+	if faces, exists := fr.families[key]; exists {
+		if f, exists := faces[style]; exists {
+			return f, false
+		}
+
+		switch style {
+		case FontBoldItalic:
+			// Prefer keeping the weight and slanting it, then a real italic
+			if f, exists := faces[FontBold]; exists {
+				return f, true
+			}
+			if f, exists := faces[FontItalic]; exists {
+				return f, false
+			}
+		}
+
+		if f, exists := faces[FontRegular]; exists {
+			return f, italic
+		}
+	}
+
+	return fr.builtinFont(builtinFace{mono: key == MonoFamily, style: style}), false
+}
+
+// FamilyName returns the name a family resolves to: its registered name, or
+// "Go" / "Go Mono" for the built-in fonts
+func (fr *FontRegistry) FamilyName(family string) string {
+	key := fr.familyKey(family)
+	if name, exists := fr.names[key]; exists {
+		return name
+	}
+	if key == MonoFamily {
+		return "Go Mono"
+	}
+	return "Go"
+}
+
+// genericFamilies maps generic family names to the built-in font they
+// stand for: "" for Go, MonoFamily for Go Mono
+var genericFamilies = map[string]string{
+	"serif":      "",
+	"sans-serif": "",
+	"system-ui":  "",
+	"monospace":  MonoFamily,
+	MonoFamily:   MonoFamily,
+}
+
+// familyKey returns the lookup key of the family a font family resolves to.
+// A comma-separated font stack like "Beleren, MPlantin, sans-serif" resolves
+// to its first registered or generic family, or else its last entry, which
+// falls back to the Go fonts.
+func (fr *FontRegistry) familyKey(family string) string {
+	var key string
+	for _, entry := range strings.Split(family, ",") {
+		key = strings.ToLower(strings.Trim(strings.TrimSpace(entry), `"'`))
+		if _, exists := fr.families[key]; exists {
+			return key
+		}
+		if generic, exists := genericFamilies[key]; exists {
+			return generic
+		}
+	}
+	return key
+}
+
+// add stores a parsed font under a family and style
+func (fr *FontRegistry) add(family string, style FontStyle, f *truetype.Font, source string) {
+	key := strings.ToLower(strings.TrimSpace(family))
+	if fr.families[key] == nil {
+		fr.families[key] = make(map[FontStyle]*truetype.Font)
+		fr.sources[key] = make(map[FontStyle]string)
+		fr.names[key] = strings.TrimSpace(family)
+	}
+	fr.families[key][style] = f
+	fr.sources[key][style] = source
+}
+
+// builtinFont lazily parses and caches a Go font
+func (fr *FontRegistry) builtinFont(face builtinFace) *truetype.Font {
+	if f, exists := fr.builtin[face]; exists {
+		return f
+	}
+
+	var fontData []byte
+	switch face {
+	case builtinFace{false, FontBoldItalic}:
+		fontData = gobolditalic.TTF
+	case builtinFace{false, FontBold}:
+		fontData = gobold.TTF
+	case builtinFace{false, FontItalic}:
+		fontData = goitalic.TTF
+	case builtinFace{true, FontRegular}:
+		fontData = gomono.TTF
+	case builtinFace{true, FontBoldItalic}:
+		fontData = gomonobolditalic.TTF
+	case builtinFace{true, FontBold}:
+		fontData = gomonobold.TTF
+	case builtinFace{true, FontItalic}:
+		fontData = gomonoitalic.TTF
+	default:
+		fontData = goregular.TTF
+	}
+
+	f, err := truetype.Parse(fontData)
+	if err != nil {
+		// Fallback to regular font
+		fontData = goregular.TTF
+		f, _ = truetype.Parse(fontData)
+	}
+
+	fr.builtin[face] = f
+	fr.data[f] = fontData
+	return f
+}
+
+// styleFor maps bold/italic flags to a FontStyle
+func styleFor(bold, italic bool) FontStyle {
+	switch {
+	case bold && italic:
+		return FontBoldItalic
+	case bold:
+		return FontBold
+	case italic:
+		return FontItalic
+	default:
+		return FontRegular
+	}
+}
+
+// parseFontStyle maps a subfamily name like "Bold Italic" to a FontStyle
+func parseFontStyle(subfamily string) FontStyle {
+	lower := strings.ToLower(subfamily)
+	bold := strings.Contains(lower, "bold")
+	italic := strings.Contains(lower, "italic") || strings.Contains(lower, "oblique")
+	return styleFor(bold, italic)
+}
+
+// splitFontStyle splits a name like "Beleren-BoldItalic" into family and style
+func splitFontStyle(name string) (string, FontStyle) {
+	idx := strings.LastIndex(name, "-")
+	if idx <= 0 {
+		return name, FontRegular
+	}
+
+	suffix := strings.ToLower(name[idx+1:])
+	switch suffix {
+	case "regular":
+		return name[:idx], FontRegular
+	case "bold", "italic", "oblique", "bolditalic", "boldoblique":
+		return name[:idx], parseFontStyle(suffix)
+	}
+
+	return name, FontRegular
+}
+
+// obliqueFace synthesizes an italic by shearing the glyphs of an upright face
+type obliqueFace struct {
+	font.Face
+	slant float64
+}
+
+// Glyph returns the upright glyph mask sheared to the right above the baseline
+func (o *obliqueFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	dr, mask, maskp, advance, ok := o.Face.Glyph(dot, r)
+	if !ok || dr.Empty() {
+		return dr, mask, maskp, advance, ok
+	}
+
+	baseline := dot.Y.Round()
+	shiftFor := func(y int) int {
+		return int(math.Round(o.slant * float64(baseline-y)))
+	}
+
+	// Rows above the baseline move right, rows below it move left
+	sheared := image.Rect(dr.Min.X+shiftFor(dr.Max.Y-1), dr.Min.Y, dr.Max.X+shiftFor(dr.Min.Y), dr.Max.Y)
+	out := image.NewAlpha(sheared)
+
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		shift := shiftFor(y)
+		for x := dr.Min.X; x < dr.Max.X; x++ {
+			_, _, _, a := mask.At(maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y).RGBA()
+			if a == 0 {
+				continue
+			}
+			out.SetAlpha(x+shift, y, color.Alpha{A: uint8(a >> 8)})
+		}
+	}
+
+	return sheared, out, sheared.Min, advance, true
+}
@@ -0,0 +1,64 @@
+package renderer
+
+import (
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomedium"
+)
+
+func TestFontStack(t *testing.T) {
+	fonts := NewFontRegistry()
+	if err := fonts.Register("MPlantin", gomedium.TTF); err != nil {
+		t.Fatalf("failed to register font: %v", err)
+	}
+
+	tests := []struct {
+		family string
+		want   string
+	}{
+		{"MPlantin", "MPlantin"},
+		{"Beleren, MPlantin, sans-serif", "MPlantin"},
+		{"'Beleren', \"mplantin\"", "MPlantin"},
+		{"Beleren, sans-serif, MPlantin", "Go"},
+		{"Beleren, monospace", "Go Mono"},
+		{"Beleren, Matrix", "Go"},
+		{"mono", "Go Mono"},
+	}
+
+	for _, test := range tests {
+		if got := fonts.FamilyName(test.family); got != test.want {
+			t.Errorf("FamilyName(%q) = %q, want %q", test.family, got, test.want)
+		}
+	}
+
+	// The stack must pick the registered face, not the Go fallback
+	want, _ := fonts.Font("MPlantin", false, false)
+	if got, _ := fonts.Font("Beleren, MPlantin", false, false); got != want {
+		t.Errorf("font stack did not resolve to the registered MPlantin face")
+	}
+}
+
+func TestEmojiFallback(t *testing.T) {
+	fonts := NewFontRegistry()
+	regular := fonts.Face("sans-serif", 20, false, false)
+	mono := fonts.Face("mono", 20, false, false)
+
+	// The Go fonts share their coverage, so pretend only mono has the star
+	face := &fallbackFace{
+		Face:       regular,
+		fallback:   mono,
+		inPrimary:  func(r rune) bool { return r != '★' && r != '\ufe0f' },
+		inFallback: func(r rune) bool { return r == '★' },
+	}
+
+	wantA, _ := regular.GlyphAdvance('A')
+	wantStar, _ := mono.GlyphAdvance('★')
+	if got := font.MeasureString(face, "A★"); got != wantA+wantStar {
+		t.Errorf("advance of A and a star = %v, want %v with the star from the fallback", got, wantA+wantStar)
+	}
+	// A presentation selector neither font has takes no space
+	if got := font.MeasureString(face, "★\ufe0f"); got != wantStar {
+		t.Errorf("advance of a star with a variation selector = %v, want %v", got, wantStar)
+	}
+}
@@ -0,0 +1,107 @@
+package renderer
+
+import (
+	"flag"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// updateGolden rewrites the golden images from the current renderer:
+// go test ./pkg/renderer -update-golden
+var updateGolden = flag.Bool("update-golden", false, "regenerate golden images instead of comparing against them")
+
+// goldenDir holds one directory per golden case: card.md, template.yaml, any
+// images the template uses, and the expected render golden.png
+const goldenDir = "testdata/golden"
+
+// goldenTolerance absorbs antialiasing differences between platforms
+const goldenTolerance = 8
+
+// TestGolden renders every case under testdata/golden and compares it to its
+// golden.png
+func TestGolden(t *testing.T) {
+	entries, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", goldenDir, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		t.Run(name, func(t *testing.T) {
+			checkGolden(t, filepath.Join(goldenDir, name))
+		})
+	}
+}
+
+// checkGolden renders a golden case and compares it to its golden.png, or
+// writes golden.png with -update-golden. On a mismatch the render and a diff
+// image are kept in a temp directory for inspection.
+func checkGolden(t *testing.T, dir string) {
+	t.Helper()
+
+	img := renderGolden(t, dir)
+	goldenPath := filepath.Join(dir, "golden.png")
+
+	r := NewRenderer()
+	if *updateGolden {
+		if err := r.SaveImage(img, goldenPath); err != nil {
+			t.Fatalf("failed to write %s: %v", goldenPath, err)
+		}
+		return
+	}
+
+	want, err := r.imageProcessor.LoadImage(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to load golden image (run with -update-golden to create it): %v", err)
+	}
+
+	comparison, err := Compare(img, want, goldenTolerance)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if comparison.Matches() {
+		return
+	}
+
+	out := t.TempDir()
+	renderedPath := filepath.Join(out, "rendered.png")
+	diffPath := filepath.Join(out, "diff.png")
+	if err := r.SaveImage(img, renderedPath); err != nil {
+		t.Errorf("failed to save rendered image: %v", err)
+	}
+	if err := r.SaveImage(comparison.Diff, diffPath); err != nil {
+		t.Errorf("failed to save diff image: %v", err)
+	}
+	t.Errorf("%d pixels (%.2f%%) differ from %s; see %s and %s",
+		comparison.DiffPixels, comparison.Percent(), goldenPath, renderedPath, diffPath)
+}
+
+// renderGolden renders a golden case's card with its template using a fresh
+// renderer at the default seed
+func renderGolden(t *testing.T, dir string) image.Image {
+	t.Helper()
+
+	card, err := metadata.NewParser().ParseFile(filepath.Join(dir, "card.md"))
+	if err != nil {
+		t.Fatalf("failed to parse card: %v", err)
+	}
+
+	template, err := templates.NewManager().LoadTemplateFile(filepath.Join(dir, "template.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+
+	img, err := NewRenderer().RenderImage(card, template)
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	return img
+}
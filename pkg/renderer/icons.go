@@ -0,0 +1,80 @@
+package renderer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// iconReference matches an {{icon}} or {{icon(param)}} token left in text
+// after variable substitution
+var iconReference = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w.]*)\s*(?:\(([^(){}]*)\))?\s*\}\}`)
+
+// checkIcons reports icons an icon_replace layer refers to that the template
+// doesn't define or whose image can't be found. Problems are warnings, or
+// fail the layer with strict images.
+func (r *Renderer) checkIcons(layer templates.Layer, vars map[string]string, template *templates.Template) error {
+	content := r.variableProcessor.SubstituteVariables(selectContent(layer, vars), vars)
+	problems := r.missingIcons(content, template, vars)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if r.strictImages {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	for _, problem := range problems {
+		r.log.Warnf("Card '%s', layer %s: %s", vars["card.title"], layer.Name, problem)
+	}
+	return nil
+}
+
+// missingIcons describes each distinct icon token in content with no entry in
+// the template's icons map, or whose image isn't in any icon directory
+func (r *Renderer) missingIcons(content string, template *templates.Template, vars map[string]string) []string {
+	var problems []string
+	seen := make(map[string]bool)
+
+	for _, match := range iconReference.FindAllStringSubmatch(content, -1) {
+		key, param := match[1], match[2]
+		if seen[match[0]] {
+			continue
+		}
+		seen[match[0]] = true
+
+		source, exists := template.Icons[key]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("icon '%s' is not defined in the template's icons", key))
+			continue
+		}
+
+		if path, found := r.findIcon(strings.ReplaceAll(source, "{{param}}", param), template, vars); !found {
+			problems = append(problems, fmt.Sprintf("icon '%s' has no image (looked for %s)", key, path))
+		}
+	}
+
+	return problems
+}
+
+// findIcon looks for an icon's image in each icon directory, returning the
+// first path tried and whether any exists. Icons that alias another variable
+// rather than naming a file, and remote images, count as found.
+func (r *Renderer) findIcon(source string, template *templates.Template, vars map[string]string) (string, bool) {
+	first := ""
+	for _, candidate := range expandIconDirs([]string{source}, template.IconDirs) {
+		path := r.variableProcessor.SubstituteVariables(candidate, vars)
+		if strings.Contains(path, "{{") || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+			return path, true
+		}
+		if first == "" {
+			first = path
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return first, false
+}
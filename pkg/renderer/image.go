@@ -1,26 +1,47 @@
 package renderer
 
 import (
+	"bufio"
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/Merith-TK/tcg-cardgen/pkg/logger"
 	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+	"github.com/Merith-TK/tcg-cardgen/pkg/types"
 	"github.com/fogleman/gg"
+	xdraw "golang.org/x/image/draw"
 )
 
+// imageDownloadTimeout bounds how long an image URL may take to download
+const imageDownloadTimeout = 30 * time.Second
+
 // ImageProcessor handles all image-related operations
 type ImageProcessor struct {
-	cache map[string]image.Image
+	cache        map[string]image.Image
+	adjusted     map[string]image.Image // Tone-adjusted images keyed by path and parameters
+	trimmed      map[string]image.Image // Border-trimmed images keyed by path and trim color
+	interpolator xdraw.Interpolator     // Resampling used when fitting images to regions
+	sharpen      bool                   // Unsharp mask downscaled images
+	log          *logger.Logger
+	cacheStats   types.CacheStats // Image loads served from cache
 }
 
 // NewImageProcessor creates a new image processor
 func NewImageProcessor() *ImageProcessor {
 	return &ImageProcessor{
-		cache: make(map[string]image.Image),
+		cache:        make(map[string]image.Image),
+		adjusted:     make(map[string]image.Image),
+		trimmed:      make(map[string]image.Image),
+		interpolator: xdraw.BiLinear,
+		log:          logger.New(logger.LevelInfo),
 	}
 }
 
@@ -28,8 +49,10 @@ func NewImageProcessor() *ImageProcessor {
 func (ip *ImageProcessor) LoadImage(path string) (image.Image, error) {
 	// Check cache first
 	if img, exists := ip.cache[path]; exists {
+		ip.cacheStats.Hits++
 		return img, nil
 	}
+	ip.cacheStats.Misses++
 
 	var img image.Image
 	var err error
@@ -44,7 +67,7 @@ func (ip *ImageProcessor) LoadImage(path string) (image.Image, error) {
 		}
 
 		// Load local image
-		img, err = gg.LoadImage(path)
+		img, err = ip.loadFile(path)
 	}
 
 	if err != nil {
@@ -58,7 +81,8 @@ func (ip *ImageProcessor) LoadImage(path string) (image.Image, error) {
 
 // downloadImage downloads an image from a URL
 func (ip *ImageProcessor) downloadImage(url string) (image.Image, error) {
-	resp, err := http.Get(url)
+	client := http.Client{Timeout: imageDownloadTimeout}
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download image: %v", err)
 	}
@@ -69,7 +93,7 @@ func (ip *ImageProcessor) downloadImage(url string) (image.Image, error) {
 	}
 
 	// Decode the image
-	img, _, err := image.Decode(resp.Body)
+	img, err := ip.decodeImage(resp.Body, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image: %v", err)
 	}
@@ -77,6 +101,50 @@ func (ip *ImageProcessor) downloadImage(url string) (image.Image, error) {
 	return img, nil
 }
 
+// loadFile decodes a local image file
+func (ip *ImageProcessor) loadFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ip.decodeImage(file, path)
+}
+
+// decodeImage decodes an image in any registered format. GIFs are decoded
+// explicitly so animated ones reliably give their first frame.
+func (ip *ImageProcessor) decodeImage(r io.Reader, source string) (image.Image, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(4); string(magic) != "GIF8" {
+		img, _, err := image.Decode(br)
+		return img, err
+	}
+
+	anim, err := gif.DecodeAll(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(anim.Image) > 1 {
+		ip.log.Infof("Image %s has %d frames, only the first is used", source, len(anim.Image))
+	}
+	return firstFrame(anim), nil
+}
+
+// firstFrame returns a GIF's first frame on the GIF's full canvas, since a
+// frame may cover only part of it
+func firstFrame(anim *gif.GIF) image.Image {
+	frame := anim.Image[0]
+	canvas := image.Rect(0, 0, anim.Config.Width, anim.Config.Height)
+	if canvas.Empty() || frame.Bounds() == canvas {
+		return frame
+	}
+
+	img := image.NewRGBA(canvas)
+	draw.Draw(img, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+	return img
+}
+
 // CreateFittedImage creates a new image that fits the specified region with the given fit mode
 func (ip *ImageProcessor) CreateFittedImage(img image.Image, region templates.Region, fitMode string) image.Image {
 	imgBounds := img.Bounds()
@@ -107,9 +175,9 @@ func (ip *ImageProcessor) CreateFittedImage(img image.Image, region templates.Re
 		drawX := (regionWidth - scaledWidth) / 2
 		drawY := (regionHeight - scaledHeight) / 2
 
-		// Scale and draw the image
-		fittedDC.Scale(scale, scale)
-		fittedDC.DrawImageAnchored(img, int(drawX/scale+imgWidth/2), int(drawY/scale+imgHeight/2), 0.5, 0.5)
+		// Scale and draw the image, centered
+		ip.drawScaled(fittedDC.Image().(*image.RGBA), img, scale,
+			int(drawX/scale+imgWidth/2)-int(imgWidth/2), int(drawY/scale+imgHeight/2)-int(imgHeight/2))
 
 	case "fit": // Scale to fit entirely within region, may leave empty space
 		// Calculate scaling to fit within the region
@@ -128,12 +196,13 @@ func (ip *ImageProcessor) CreateFittedImage(img image.Image, region templates.Re
 		drawX := (regionWidth - scaledWidth) / 2
 		drawY := (regionHeight - scaledHeight) / 2
 
-		// Scale and draw the image
-		fittedDC.Scale(scale, scale)
-		fittedDC.DrawImageAnchored(img, int(drawX/scale+imgWidth/2), int(drawY/scale+imgHeight/2), 0.5, 0.5)
+		// Scale and draw the image, centered
+		ip.drawScaled(fittedDC.Image().(*image.RGBA), img, scale,
+			int(drawX/scale+imgWidth/2)-int(imgWidth/2), int(drawY/scale+imgHeight/2)-int(imgHeight/2))
 
 	case "stretch": // Stretch to exact region dimensions (may distort)
-		fittedDC.DrawImageAnchored(img, region.Width/2, region.Height/2, 0.5, 0.5)
+		dst := fittedDC.Image().(*image.RGBA)
+		ip.interpolator.Scale(dst, dst.Bounds(), img, imgBounds, xdraw.Over, nil)
 
 	case "center": // No scaling, just center (may crop or leave empty space)
 		drawX := (regionWidth - imgWidth) / 2
@@ -147,23 +216,30 @@ func (ip *ImageProcessor) CreateFittedImage(img image.Image, region templates.Re
 	return fittedDC.Image()
 }
 
-// RenderPlaceholder renders a placeholder rectangle with text
-func (ip *ImageProcessor) RenderPlaceholder(dc *gg.Context, layer templates.Layer, text string) {
+// RenderPlaceholder renders a placeholder rectangle with text.
+// A nil border or empty text skips drawing it.
+func (ip *ImageProcessor) RenderPlaceholder(dc *gg.Context, layer templates.Layer, text string, fill, border, textColor color.Color, borderWidth float64) {
 	// Draw placeholder rectangle
-	dc.SetColor(color.RGBA{200, 200, 200, 255})
+	dc.SetColor(fill)
 	dc.DrawRectangle(float64(layer.Region.X), float64(layer.Region.Y),
 		float64(layer.Region.Width), float64(layer.Region.Height))
 	dc.Fill()
 
 	// Draw border
-	dc.SetColor(color.RGBA{100, 100, 100, 255})
-	dc.SetLineWidth(2)
-	dc.DrawRectangle(float64(layer.Region.X), float64(layer.Region.Y),
-		float64(layer.Region.Width), float64(layer.Region.Height))
-	dc.Stroke()
+	if border != nil {
+		dc.SetColor(border)
+		dc.SetLineWidth(borderWidth)
+		dc.DrawRectangle(float64(layer.Region.X), float64(layer.Region.Y),
+			float64(layer.Region.Width), float64(layer.Region.Height))
+		dc.Stroke()
+	}
+
+	if text == "" {
+		return
+	}
 
 	// Draw text
-	dc.SetColor(color.RGBA{50, 50, 50, 255})
+	dc.SetColor(textColor)
 	dc.DrawStringAnchored(text,
 		float64(layer.Region.X+layer.Region.Width/2),
 		float64(layer.Region.Y+layer.Region.Height/2),
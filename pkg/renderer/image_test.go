@@ -0,0 +1,84 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+func TestAnimatedGIF(t *testing.T) {
+	palette := color.Palette{color.Transparent, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	frame := func(bounds image.Rectangle, index uint8) *image.Paletted {
+		img := image.NewPaletted(bounds, palette)
+		for i := range img.Pix {
+			img.Pix[i] = index
+		}
+		return img
+	}
+
+	// The first frame covers only the top left of the canvas
+	anim := &gif.GIF{
+		Image:  []*image.Paletted{frame(image.Rect(0, 0, 4, 4), 1), frame(image.Rect(0, 0, 8, 8), 2)},
+		Delay:  []int{10, 10},
+		Config: image.Config{ColorModel: palette, Width: 8, Height: 8},
+	}
+	path := filepath.Join(t.TempDir(), "art.gif")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create GIF: %v", err)
+	}
+	if err := gif.EncodeAll(file, anim); err != nil {
+		t.Fatalf("failed to encode GIF: %v", err)
+	}
+	file.Close()
+
+	img, err := NewImageProcessor().LoadImage(path)
+	if err != nil {
+		t.Fatalf("failed to load GIF: %v", err)
+	}
+
+	if got := img.Bounds(); got != image.Rect(0, 0, 8, 8) {
+		t.Errorf("bounds = %v, want the full 8x8 canvas", got)
+	}
+	if r, _, b, _ := img.At(1, 1).RGBA(); r>>8 != 255 || b != 0 {
+		t.Errorf("pixel (1,1) = %v, want the first frame's red", img.At(1, 1))
+	}
+	if _, _, _, a := img.At(6, 6).RGBA(); a != 0 {
+		t.Errorf("pixel (6,6) = %v, want transparent outside the first frame", img.At(6, 6))
+	}
+}
+
+func TestStretchFit(t *testing.T) {
+	// Left half red, right half blue
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				src.Set(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				src.Set(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+
+	region := templates.Region{Width: 20, Height: 40}
+	img := NewImageProcessor().CreateFittedImage(src, region, "stretch")
+	if got := img.Bounds(); got != image.Rect(0, 0, 20, 40) {
+		t.Fatalf("bounds = %v, want the 20x40 region", got)
+	}
+
+	// Stretching fills the region top to bottom, unlike fit's letterboxing
+	for _, y := range []int{0, 20, 39} {
+		if r, _, b, a := img.At(1, y).RGBA(); r>>8 != 255 || b != 0 || a>>8 != 255 {
+			t.Errorf("pixel (1,%d) = %v, want red", y, img.At(1, y))
+		}
+		if r, _, b, a := img.At(18, y).RGBA(); r != 0 || b>>8 != 255 || a>>8 != 255 {
+			t.Errorf("pixel (18,%d) = %v, want blue", y, img.At(18, y))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package renderer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// keywordSeparator matches the comma/semicolon between keywords on a keyword line
+var keywordSeparator = regexp.MustCompile(`^\s*[,;]\s*`)
+
+// BoldKeywords wraps known ability keywords in **...** where they lead a line.
+// Matching is case-insensitive and word-boundary aware, and a comma or
+// semicolon separated run ("Flying, trample") bolds each keyword in it.
+func (tp *TextProcessor) BoldKeywords(content string, keywords []string) string {
+	pattern := keywordPattern(keywords)
+	if pattern == nil {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = boldLeadingKeywords(line, pattern)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keywordPattern builds a regex matching any keyword at the start of the input
+func keywordPattern(keywords []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			quoted = append(quoted, regexp.QuoteMeta(keyword))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	// Longest first so "First strike" wins over "First"
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile(`(?i)^(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// boldLeadingKeywords bolds the run of keywords at the start of a single line
+func boldLeadingKeywords(line string, pattern *regexp.Regexp) string {
+	rest := strings.TrimLeft(line, " \t")
+	var out strings.Builder
+	out.WriteString(line[:len(line)-len(rest)])
+
+	for {
+		match := pattern.FindString(rest)
+		if match == "" {
+			break
+		}
+		out.WriteString("**" + match + "**")
+		rest = rest[len(match):]
+
+		// Continue only across a separator followed by another keyword
+		sep := keywordSeparator.FindString(rest)
+		if sep == "" || pattern.FindString(rest[len(sep):]) == "" {
+			break
+		}
+		out.WriteString(sep)
+		rest = rest[len(sep):]
+	}
+
+	out.WriteString(rest)
+	return out.String()
+}
@@ -0,0 +1,118 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// layerResult is a drawn layer kept for reuse by later cards
+type layerResult struct {
+	img     *image.RGBA  // The layer's pixels, cropped to what it drew
+	outcome layerOutcome // What the layer drew, for the verbose trace
+}
+
+// layerCache reuses layers that draw the same thing on several cards, such
+// as a frame shared by all commons. A layer is only kept once its key has
+// been seen twice, so per-card layers like titles cost nothing extra.
+type layerCache struct {
+	seen    map[string]bool
+	results map[string]*layerResult
+}
+
+// SetLayerCache enables reusing drawn layers across cards when every input
+// of the layer resolves the same
+func (r *Renderer) SetLayerCache(enabled bool) {
+	r.layerCache = nil
+	if enabled {
+		r.layerCache = &layerCache{
+			seen:    make(map[string]bool),
+			results: make(map[string]*layerResult),
+		}
+	}
+}
+
+// layerKey identifies what a layer draws for a card: the template, the
+// layer and the resolved value of each of its inputs. Layers taking part in
+// text overflow depend on other layers and are never cached.
+func (r *Renderer) layerKey(layer templates.Layer, vars map[string]string, template *templates.Template) (string, bool) {
+	if layer.OverflowInto != "" || isOverflowTarget(layer, template) {
+		return "", false
+	}
+
+	layer.Content = selectContent(layer, vars)
+	key := []string{fmt.Sprintf("%p", template), layer.Name, layer.Variant, vars["card.artwork.fit"]}
+	for _, field := range layerInputs(layer, template) {
+		key = append(key, r.variableProcessor.SubstituteVariables(field, vars))
+	}
+	return strings.Join(key, "\x00"), true
+}
+
+// drawCachedLayer draws a layer from the layer cache, rendering and storing
+// it when its key is seen for the second time. It reports false when the
+// layer isn't cached and must be drawn directly.
+func (r *Renderer) drawCachedLayer(dc *gg.Context, layer templates.Layer, vars map[string]string, template *templates.Template, render func(*gg.Context) (layerOutcome, error)) (layerOutcome, bool, error) {
+	if r.layerCache == nil || r.svg != nil {
+		return layerOutcome{}, false, nil
+	}
+	key, cacheable := r.layerKey(layer, vars, template)
+	if !cacheable {
+		return layerOutcome{}, false, nil
+	}
+
+	if result, exists := r.layerCache.results[key]; exists {
+		result.drawOnto(dc)
+		outcome := result.outcome
+		outcome.trace += " (from layer cache)"
+		return outcome, true, nil
+	}
+	if !r.layerCache.seen[key] {
+		r.layerCache.seen[key] = true
+		return layerOutcome{}, false, nil
+	}
+
+	// Seen before: draw it on its own so the pixels can be kept
+	layerDC := gg.NewContext(dc.Width(), dc.Height())
+	outcome, err := render(layerDC)
+	if err != nil {
+		return layerOutcome{}, true, err
+	}
+
+	result := &layerResult{img: cropToContent(layerDC.Image().(*image.RGBA)), outcome: outcome}
+	r.layerCache.results[key] = result
+	result.drawOnto(dc)
+	return outcome, true, nil
+}
+
+// drawOnto composites the kept layer over the card at its original position
+func (l *layerResult) drawOnto(dc *gg.Context) {
+	draw.Draw(dc.Image().(*image.RGBA), l.img.Rect, l.img, l.img.Rect.Min, draw.Over)
+}
+
+// cropToContent returns the smallest part of img holding every pixel that
+// isn't fully transparent, keeping its position on the card
+func cropToContent(img *image.RGBA) *image.RGBA {
+	minX, minY, maxX, maxY := img.Rect.Max.X, img.Rect.Max.Y, img.Rect.Min.X, img.Rect.Min.Y
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			if img.Pix[img.PixOffset(x, y)+3] == 0 {
+				continue
+			}
+			minX, maxX = min(minX, x), max(maxX, x+1)
+			minY, maxY = min(minY, y), max(maxY, y+1)
+		}
+	}
+	bounds := image.Rect(minX, minY, maxX, maxY)
+	if minX >= maxX {
+		bounds = image.Rectangle{}
+	}
+
+	cropped := image.NewRGBA(bounds)
+	draw.Draw(cropped, bounds, img, bounds.Min, draw.Src)
+	return cropped
+}
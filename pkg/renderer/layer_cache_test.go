@@ -0,0 +1,47 @@
+package renderer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+func TestLayerCache(t *testing.T) {
+	dir := filepath.Join(goldenDir, "wrapped_body")
+	card, err := metadata.NewParser().ParseFile(filepath.Join(dir, "card.md"))
+	if err != nil {
+		t.Fatalf("failed to parse card: %v", err)
+	}
+	template, err := templates.NewManager().LoadTemplateFile(filepath.Join(dir, "template.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+
+	want, err := NewRenderer().RenderImage(card, template)
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	r := NewRenderer()
+	r.SetLayerCache(true)
+	for i := 1; i <= 3; i++ {
+		got, err := r.RenderImage(card, template)
+		if err != nil {
+			t.Fatalf("render %d: %v", i, err)
+		}
+		// Compositing a kept layer may round antialiased edges differently by one
+		comparison, err := Compare(got, want, 1)
+		if err != nil {
+			t.Fatalf("render %d: %v", i, err)
+		}
+		if !comparison.Matches() {
+			t.Errorf("render %d: %d pixels differ from the uncached render", i, comparison.DiffPixels)
+		}
+	}
+
+	if len(r.layerCache.results) != 1 {
+		t.Errorf("got %d cached layers, want 1 (the body layer)", len(r.layerCache.results))
+	}
+}
@@ -0,0 +1,53 @@
+package renderer
+
+import "github.com/Merith-TK/tcg-cardgen/pkg/templates"
+
+// SetLayerFilter restricts rendering to the named layers (only) and/or drops
+// the named layers (skip). Empty lists disable the respective filter.
+func (r *Renderer) SetLayerFilter(only, skip []string) {
+	r.onlyLayers = only
+	r.skipLayers = skip
+	r.filtered = make(map[*templates.Template]*templates.Template)
+}
+
+// filterLayers returns the template with the layer filter applied. The filtered
+// copy is reused per template so its cached background stays valid.
+func (r *Renderer) filterLayers(template *templates.Template) *templates.Template {
+	if len(r.onlyLayers) == 0 && len(r.skipLayers) == 0 {
+		return template
+	}
+	if filtered, exists := r.filtered[template]; exists {
+		return filtered
+	}
+
+	known := make(map[string]bool)
+	for _, layer := range template.Layers {
+		known[layer.Name] = true
+	}
+	only := r.layerSet(r.onlyLayers, known, template)
+	skip := r.layerSet(r.skipLayers, known, template)
+
+	filtered := *template
+	filtered.Layers = nil
+	for _, layer := range template.Layers {
+		if (len(only) > 0 && !only[layer.Name]) || skip[layer.Name] {
+			continue
+		}
+		filtered.Layers = append(filtered.Layers, layer)
+	}
+
+	r.filtered[template] = &filtered
+	return &filtered
+}
+
+// layerSet builds a lookup of layer names, warning about names the template lacks
+func (r *Renderer) layerSet(names []string, known map[string]bool, template *templates.Template) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range names {
+		if !known[name] {
+			r.log.Warnf("layer filter: template '%s' has no layer '%s'", template.Name, name)
+		}
+		set[name] = true
+	}
+	return set
+}
@@ -0,0 +1,54 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+func TestRenderLayout(t *testing.T) {
+	template := &templates.Template{
+		Name:       "layout",
+		Dimensions: templates.Dimensions{Width: 100, Height: 80},
+		Layers: []templates.Layer{
+			{Name: "label", Type: "text", Content: "Static", Region: templates.Region{X: 0, Y: 0, Width: 100, Height: 20}},
+			{Name: "title", Type: "text", Content: "{{card.title}}", Region: templates.Region{X: 0, Y: 20, Width: 100, Height: 20}},
+			{Name: "flavor", Type: "text", Content: "{{card.flavor}}", Condition: "{{card.flavor}}", Region: templates.Region{X: 0, Y: 40, Width: 100, Height: 20}},
+			{Name: "footer", Type: "text", Content: "{{card.footer}}", Region: templates.Region{X: 0, Y: 60, Width: 100, Height: 20}},
+		},
+	}
+	card := &metadata.Card{TCG: "mtg", Title: "Shock", PrintThis: 1, PrintTotal: 1}
+
+	r := NewRenderer()
+	if err := r.SetScale(2); err != nil {
+		t.Fatalf("failed to set scale: %v", err)
+	}
+	img, results, err := r.RenderLayout(card, template)
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if img.Bounds().Dx() != 200 {
+		t.Errorf("image width = %d, want 200", img.Bounds().Dx())
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d layer results, want 4", len(results))
+	}
+
+	label, title, flavor, footer := results[0], results[1], results[2], results[3]
+	if !label.Drawn || !strings.Contains(label.Reason, "cached static background") {
+		t.Errorf("label = %+v, want drawn from the static background", label)
+	}
+	// Regions are reported as drawn, after scaling
+	if want := (templates.Region{X: 0, Y: 40, Width: 200, Height: 40}); !title.Drawn || title.Region != want || title.Text != "Shock" {
+		t.Errorf("title = %+v, want drawn text %q in %+v", title, "Shock", want)
+	}
+	if flavor.Drawn || !strings.HasPrefix(flavor.Reason, "skipped, condition") {
+		t.Errorf("flavor = %+v, want skipped by its condition", flavor)
+	}
+	// A layer that draws nothing is reported as not drawn
+	if footer.Drawn || footer.Reason != "skipped, content is empty" {
+		t.Errorf("footer = %+v, want skipped for empty content", footer)
+	}
+}
@@ -0,0 +1,105 @@
+package renderer
+
+import (
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// TextOverflow describes a text layer whose content doesn't fit its region
+type TextOverflow struct {
+	Layer     string
+	Needed    float64 // Height the wrapped text requires
+	Available float64 // Height of the layer's region
+}
+
+// CheckTextOverflow measures every visible text layer of a card without
+// rendering an image and reports the ones whose text exceeds their region.
+// Text spilled into an overflow_into layer only counts if that layer
+// can't hold it either, or never draws it. Heights are in template pixels,
+// measured at the render scale so they match what is drawn.
+func (r *Renderer) CheckTextOverflow(card *metadata.Card, template *templates.Template) []TextOverflow {
+	template = r.scaleTemplate(template)
+	vars := r.variableProcessor.BuildTemplateVariables(card, template)
+
+	// Scratch context used only for font measurement
+	dc := gg.NewContext(1, 1)
+
+	var overflows []TextOverflow
+	spilled := make(map[string][]FormattedLine)
+	pending := make(map[string]TextOverflow) // Overflows handed to a target not yet drawn
+	for _, layer := range template.Layers {
+		if layer.Type != "text" {
+			continue
+		}
+		if !r.variantSelected(layer, template, vars) {
+			continue
+		}
+		if layer.Condition != "" && !r.utils.EvaluateCondition(layer.Condition, vars) {
+			continue
+		}
+
+		content := r.prepareTextContent(layer, vars, template)
+		if layer.Raw {
+			if content == "" {
+				continue
+			}
+			needed := r.textProcessor.MeasureRawText(content, r.layerFont(layer, template, vars), vars)
+			if available := float64(layer.Region.Height); needed > available {
+				overflows = append(overflows, TextOverflow{Layer: layer.Name, Needed: needed / r.scale, Available: available / r.scale})
+			}
+			continue
+		}
+		lines := spilled[layer.Name]
+		delete(spilled, layer.Name)
+		delete(pending, layer.Name)
+		if content != "" {
+			lines = append(lines, r.layerLines(layer, content)...)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		baseFont := r.layerFont(layer, template, vars)
+
+		available := float64(layer.Region.Height)
+		var needed float64
+		if layer.Columns > 1 {
+			needed = r.textProcessor.measureColumns(dc, lines, layer, available, baseFont, vars)
+		} else {
+			needed = r.textProcessor.MeasureFormattedText(dc, lines, float64(layer.Region.Width), layer.BreakMode, baseFont, vars)
+		}
+		if needed <= available {
+			continue
+		}
+
+		overflow := TextOverflow{
+			Layer:     layer.Name,
+			Needed:    needed / r.scale,
+			Available: available / r.scale,
+		}
+		if layer.OverflowInto == "" {
+			overflows = append(overflows, overflow)
+			continue
+		}
+
+		var rest []FormattedLine
+		if layer.Columns > 1 {
+			_, rest = r.textProcessor.splitColumns(dc, lines, layer.Columns, layer.ColumnWidth(), available, layer.BreakMode, baseFont, vars)
+		} else {
+			_, rest = r.textProcessor.splitFormattedText(dc, lines, float64(layer.Region.Width), available, layer.BreakMode, baseFont, vars)
+		}
+		spilled[layer.OverflowInto] = rest
+		pending[layer.OverflowInto] = overflow
+	}
+
+	// Spilled text whose target was skipped (or doesn't exist) is still lost
+	for _, layer := range template.Layers {
+		if overflow, exists := pending[layer.OverflowInto]; exists && overflow.Layer == layer.Name {
+			overflows = append(overflows, overflow)
+		}
+	}
+
+	return overflows
+}
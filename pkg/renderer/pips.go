@@ -0,0 +1,102 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// pipFill is the fraction of each pip's slot the circle occupies
+const pipFill = 0.8
+
+// renderPipsLayer draws max circles evenly across the layer region and fills
+// the first count of them, e.g. 3 of 5 energy pips
+func (r *Renderer) renderPipsLayer(dc *gg.Context, layer templates.Layer, vars map[string]string) (layerOutcome, error) {
+	max, err := r.pipNumber(layer.Max, vars)
+	if err != nil {
+		return layerOutcome{}, fmt.Errorf("invalid pips max: %v", err)
+	}
+	count, err := r.pipNumber(layer.Count, vars)
+	if err != nil {
+		return layerOutcome{}, fmt.Errorf("invalid pips count: %v", err)
+	}
+	if max <= 0 {
+		return skippedLayer("max is 0"), nil
+	}
+	if count > max {
+		count = max
+	} else if count < 0 {
+		count = 0
+	}
+
+	filled, err := r.utils.ParseColor(r.variableProcessor.SubstituteVariables(layer.Color, vars))
+	if err != nil || layer.Color == "" {
+		filled, _ = r.utils.ParseColor("#000000")
+	}
+	emptyColor := r.variableProcessor.SubstituteVariables(layer.EmptyColor, vars)
+
+	// Each pip gets an equal slot of the region width, centered vertically
+	region := layer.Region
+	slot := float64(region.Width) / float64(max)
+	radius := math.Min(slot, float64(region.Height)) / 2 * pipFill
+	centerY := float64(region.Y) + float64(region.Height)/2
+
+	dc.Push()
+	defer dc.Pop()
+
+	for i := 0; i < max; i++ {
+		centerX := float64(region.X) + slot*(float64(i)+0.5)
+
+		if i < count {
+			r.drawPip(dc, centerX, centerY, radius, filled, nil, 0)
+			continue
+		}
+
+		// Empty pips: a solid empty_color, or an outline in the filled color
+		if empty, err := r.utils.ParseColor(emptyColor); err == nil && emptyColor != "" {
+			r.drawPip(dc, centerX, centerY, radius, empty, nil, 0)
+		} else {
+			r.drawPip(dc, centerX, centerY, radius, nil, filled, math.Max(1, radius*0.15))
+		}
+	}
+
+	return drewLayer(fmt.Sprintf("drew %d of %d pips", count, max)), nil
+}
+
+// drawPip draws one circle, filled with fill or outlined in stroke, or
+// records it while rendering SVG
+func (r *Renderer) drawPip(dc *gg.Context, cx, cy, radius float64, fill, stroke color.Color, strokeWidth float64) {
+	if r.svg != nil {
+		r.svg.circle(cx, cy, radius, fill, stroke, strokeWidth)
+		return
+	}
+
+	dc.DrawCircle(cx, cy, radius)
+	if fill != nil {
+		dc.SetColor(fill)
+		dc.Fill()
+		return
+	}
+	dc.SetColor(stroke)
+	dc.SetLineWidth(strokeWidth)
+	dc.Stroke()
+}
+
+// pipNumber resolves a count or max field to an integer; empty means zero
+func (r *Renderer) pipNumber(field string, vars map[string]string) (int, error) {
+	value := strings.TrimSpace(r.variableProcessor.SubstituteVariables(field, vars))
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a whole number", value)
+	}
+	return number, nil
+}
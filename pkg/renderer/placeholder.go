@@ -0,0 +1,107 @@
+package renderer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"math/rand"
+	"path/filepath"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// PlaceholderStyle controls how image layers without a loadable source are drawn
+type PlaceholderStyle struct {
+	Fill      string // Hex fill color; empty uses a subtle gray tint per layer
+	Border    string // Hex border color, or "none"; empty uses dark gray
+	TextColor string // Hex label color; empty uses near-black
+	HideLabel bool   // Draw a neutral box without the "Missing: file" label
+}
+
+// placeholderLook is a PlaceholderStyle with its colors parsed
+type placeholderLook struct {
+	fill      color.Color // nil uses placeholderColor
+	border    color.Color // nil draws no border
+	text      color.Color
+	hideLabel bool
+}
+
+// defaultPlaceholderLook matches the classic gray placeholder
+var defaultPlaceholderLook = placeholderLook{
+	border: color.RGBA{100, 100, 100, 255},
+	text:   color.RGBA{50, 50, 50, 255},
+}
+
+// SetPlaceholderStyle sets how missing images are drawn. On an invalid color
+// the current style is kept and an error is returned.
+func (r *Renderer) SetPlaceholderStyle(style PlaceholderStyle) error {
+	look := defaultPlaceholderLook
+	look.hideLabel = style.HideLabel
+
+	if style.Fill != "" {
+		fill, err := r.utils.ParseColor(style.Fill)
+		if err != nil {
+			return fmt.Errorf("invalid placeholder fill: %v", err)
+		}
+		look.fill = fill
+	}
+
+	switch style.Border {
+	case "":
+	case "none":
+		look.border = nil
+	default:
+		border, err := r.utils.ParseColor(style.Border)
+		if err != nil {
+			return fmt.Errorf("invalid placeholder border: %v", err)
+		}
+		look.border = border
+	}
+
+	if style.TextColor != "" {
+		text, err := r.utils.ParseColor(style.TextColor)
+		if err != nil {
+			return fmt.Errorf("invalid placeholder text color: %v", err)
+		}
+		look.text = text
+	}
+
+	r.placeholder = look
+	return nil
+}
+
+// drawPlaceholder stands in for an image layer whose sources all failed to load
+func (r *Renderer) drawPlaceholder(dc *gg.Context, layer templates.Layer, missingPath string) {
+	fill := r.placeholder.fill
+	if fill == nil {
+		fill = r.placeholderColor(layer.Name)
+	}
+
+	label := ""
+	if !r.placeholder.hideLabel {
+		label = fmt.Sprintf("Missing: %s", filepath.Base(missingPath))
+	}
+
+	if r.svg != nil {
+		region := layer.Region
+		r.svg.rect(float64(region.X), float64(region.Y), float64(region.Width), float64(region.Height), fill, r.placeholder.border, 2*r.scale)
+		if label != "" {
+			r.svg.label(label, float64(region.X)+float64(region.Width)/2, float64(region.Y)+float64(region.Height)/2, r.placeholder.text)
+		}
+		return
+	}
+	r.imageProcessor.RenderPlaceholder(dc, layer, label, fill, r.placeholder.border, r.placeholder.text, 2*r.scale)
+}
+
+// placeholderColor returns a subtle gray tint that is stable for a layer name and seed,
+// so placeholders are distinguishable but diffs between runs stay clean
+func (r *Renderer) placeholderColor(layerName string) color.Color {
+	h := fnv.New64a()
+	h.Write([]byte(layerName))
+	rng := rand.New(rand.NewSource(r.seed ^ int64(h.Sum64())))
+
+	jitter := func() uint8 { return uint8(188 + rng.Intn(25)) } // 188-212 around the classic 200 gray
+	return color.RGBA{jitter(), jitter(), jitter(), 255}
+}
@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// Print margin defaults (inches) used when a template doesn't declare its own
+const (
+	defaultDPI        = 300
+	defaultBleed      = 0.125
+	defaultSafeMargin = 0.125
+)
+
+// Proof guide colors
+var (
+	proofBleedColor = color.RGBA{220, 40, 40, 255}   // Red: bleed line (outer cut tolerance)
+	proofTrimColor  = color.RGBA{40, 90, 220, 255}   // Blue: trim line (final card edge)
+	proofSafeColor  = color.RGBA{30, 160, 60, 255}   // Green: safe zone (keep text inside)
+	proofBleedFill  = color.RGBA{255, 220, 220, 255} // Tint for the bleed area
+)
+
+// RenderProof renders a card with bleed, trim and safe-zone guides overlaid.
+// The card is centered on a canvas extended by the bleed on every side.
+func (r *Renderer) RenderProof(card *metadata.Card, template *templates.Template, outputPath string) error {
+	img, err := r.RenderImage(card, template)
+	if err != nil {
+		return err
+	}
+
+	// Margins are in inches, so they grow with the render scale like the card
+	bleed, safe := printMargins(template.Dimensions)
+	bleed = int(math.Round(float64(bleed) * r.scale))
+	safe = int(math.Round(float64(safe) * r.scale))
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+
+	dc := gg.NewContext(width+2*bleed, height+2*bleed)
+	dc.SetColor(proofBleedFill)
+	dc.Clear()
+	dc.DrawImage(img, bleed, bleed)
+
+	dc.SetLineWidth(2 * r.scale)
+	dc.SetDash(8*r.scale, 6*r.scale)
+
+	// Bleed line sits on the canvas edge
+	dc.SetColor(proofBleedColor)
+	dc.DrawRectangle(1, 1, float64(width+2*bleed-2), float64(height+2*bleed-2))
+	dc.Stroke()
+
+	// Trim line is the card's own edge
+	dc.SetColor(proofTrimColor)
+	dc.DrawRectangle(float64(bleed), float64(bleed), float64(width), float64(height))
+	dc.Stroke()
+
+	// Safe zone is inset from the trim line
+	dc.SetColor(proofSafeColor)
+	dc.DrawRectangle(float64(bleed+safe), float64(bleed+safe), float64(width-2*safe), float64(height-2*safe))
+	dc.Stroke()
+
+	if err := dc.SavePNG(outputPath); err != nil {
+		return fmt.Errorf("error saving proof to %s: %v", outputPath, err)
+	}
+
+	return nil
+}
+
+// printMargins converts a template's bleed and safe margins from inches to pixels
+func printMargins(dims templates.Dimensions) (bleed, safe int) {
+	dpi := dims.DPI
+	if dpi <= 0 {
+		dpi = defaultDPI
+	}
+
+	bleedInches := dims.Bleed
+	if bleedInches <= 0 {
+		bleedInches = defaultBleed
+	}
+
+	safeInches := dims.SafeMargin
+	if safeInches <= 0 {
+		safeInches = defaultSafeMargin
+	}
+
+	bleed = int(math.Round(bleedInches * float64(dpi)))
+	safe = int(math.Round(safeInches * float64(dpi)))
+	return bleed, safe
+}
@@ -0,0 +1,79 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// rawTabWidth is the number of spaces a tab expands to in raw text
+const rawTabWidth = 4
+
+// rawLines splits raw content into lines with tabs expanded, keeping every space
+func rawLines(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.Split(strings.ReplaceAll(content, "\t", strings.Repeat(" ", rawTabWidth)), "\n")
+}
+
+// rawMetrics returns the family, size, ascent, descent and line height raw text is set in
+func (tp *TextProcessor) rawMetrics(baseFont *templates.Font, vars map[string]string) (string, float64, float64, float64, float64) {
+	family := tp.utils.SubstituteVariables(baseFont.Family, vars)
+	size := tp.resolveFontSize(baseFont, vars)
+	metrics := tp.fonts.Face(family, size, false, false).Metrics()
+	return family, size, float64(metrics.Ascent) / 64, float64(metrics.Descent) / 64, float64(metrics.Height) / 64
+}
+
+// MeasureRawText returns the height raw text occupies, one font line per line of content
+func (tp *TextProcessor) MeasureRawText(content string, baseFont *templates.Font, vars map[string]string) float64 {
+	_, _, ascent, descent, lineHeight := tp.rawMetrics(baseFont, vars)
+	return float64(len(rawLines(content))-1)*lineHeight + ascent + descent
+}
+
+// drawRawText draws content exactly as given, without markdown or wrapping,
+// centered vertically in the region like other text
+func (tp *TextProcessor) drawRawText(dc *gg.Context, content string, x, y, w, h float64, align string, baseFont *templates.Font, vars map[string]string) {
+	family, size, ascent, _, lineHeight := tp.rawMetrics(baseFont, vars)
+	tp.setFont(dc, family, size, false, false, tp.baseColor(baseFont, vars))
+
+	baseline := y + (h-tp.MeasureRawText(content, baseFont, vars))/2 + ascent
+	for _, line := range rawLines(content) {
+		lineX := x
+		switch align {
+		case "center":
+			width, _ := dc.MeasureString(line)
+			lineX = x + (w-width)/2
+		case "right":
+			width, _ := dc.MeasureString(line)
+			lineX = x + w - width
+		}
+		tp.drawString(dc, line, lineX, baseline, 0)
+		baseline += lineHeight
+	}
+}
+
+// renderRawLayer draws a raw text layer's substituted content verbatim
+func (r *Renderer) renderRawLayer(dc *gg.Context, layer templates.Layer, content string, vars map[string]string, template *templates.Template) (layerOutcome, error) {
+	if content == "" {
+		return skippedLayer("content is empty"), nil
+	}
+
+	baseFont := r.layerFont(layer, template, vars)
+	x := float64(layer.Region.X) + layer.OffsetX
+	y := float64(layer.Region.Y) + layer.OffsetY
+	w := float64(layer.Region.Width)
+	h := float64(layer.Region.Height)
+	outcome := fmt.Sprintf("drew %d raw lines", len(rawLines(content)))
+
+	// Gradient and texture fills paint through the text's shape instead of its color
+	if (baseFont.FillGradient != nil || baseFont.FillImage != "") && r.svg == nil {
+		textDC := gg.NewContext(dc.Width(), dc.Height())
+		r.textProcessor.drawRawText(textDC, content, x, y, w, h, layer.Align, baseFont, vars)
+		return drewLayer(outcome), r.fillThroughMask(dc, textDC.AsMask(), layer, baseFont, vars)
+	}
+
+	r.textProcessor.drawRawText(dc, content, x, y, w, h, layer.Align, baseFont, vars)
+	return drewLayer(outcome), nil
+}
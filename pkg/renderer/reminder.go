@@ -0,0 +1,56 @@
+package renderer
+
+import "strings"
+
+// ItalicizeReminderText wraps parenthesized spans in *...* so reminder text
+// renders italic. Only the outermost span of nested parentheses is wrapped;
+// unbalanced parentheses, and spans already inside or containing emphasis,
+// are left alone.
+func (tp *TextProcessor) ItalicizeReminderText(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = italicizeLineReminders(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// italicizeLineReminders italicizes the balanced top-level (...) spans of one line
+func italicizeLineReminders(line string) string {
+	var out strings.Builder
+	emphasis := 0 // Unmatched '*' seen so far; odd means we're inside emphasis
+	depth, start := 0, -1
+	last := 0
+
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '*':
+			if depth == 0 {
+				emphasis++
+			}
+		case '(':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case ')':
+			if depth == 0 {
+				continue // Stray closing paren
+			}
+			depth--
+			if depth > 0 {
+				continue
+			}
+
+			span := line[start : i+1]
+			if emphasis%2 == 0 && !strings.ContainsAny(span, "*_") {
+				out.WriteString(line[last:start])
+				out.WriteString("*" + span + "*")
+				last = i + 1
+			}
+		}
+	}
+
+	// Anything after an unclosed '(' is copied through untouched
+	out.WriteString(line[last:])
+	return out.String()
+}
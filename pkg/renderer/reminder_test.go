@@ -0,0 +1,33 @@
+package renderer
+
+import "testing"
+
+func TestItalicizeReminderText(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"Flying (This creature can't be blocked.)", "Flying *(This creature can't be blocked.)*"},
+		{"Kicker {R} (You may pay (R) more.)", "Kicker {R} *(You may pay (R) more.)*"}, // Only the outer span
+		{"*(Already italic.)*", "*(Already italic.)*"},
+		{"Trample (Deals **excess** damage.)", "Trample (Deals **excess** damage.)"},
+		{"Draw a card (then discard", "Draw a card (then discard"}, // Unclosed
+		{"Stray) paren (ok)", "Stray) paren *(ok)*"},
+		{"A (one)\nB (two)", "A *(one)*\nB *(two)*"},
+	}
+
+	tp := NewTextProcessor(NewFontRegistry())
+	for _, test := range tests {
+		if got := tp.ItalicizeReminderText(test.content); got != test.want {
+			t.Errorf("ItalicizeReminderText(%q) = %q, want %q", test.content, got, test.want)
+		}
+	}
+
+	// The wrapped span is drawn italic, parentheses included
+	lines := tp.ProcessMarkdown(tp.ItalicizeReminderText("Ward (Pay 2.)"))
+	for _, segment := range lines[0].Segments {
+		if italic := segment.Content == "(Pay 2.)"; segment.Style.Italic != italic {
+			t.Errorf("segment %q italic = %v, want %v", segment.Content, segment.Style.Italic, italic)
+		}
+	}
+}
@@ -2,13 +2,20 @@ package renderer
 
 import (
 	"fmt"
+	"image"
 	"image/color"
-	"path/filepath"
+	"image/png"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
 
 	"github.com/fogleman/gg"
 
+	"github.com/Merith-TK/tcg-cardgen/pkg/logger"
 	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
 	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+	"github.com/Merith-TK/tcg-cardgen/pkg/types"
 )
 
 // Renderer handles image generation from templates and card data
@@ -17,92 +24,351 @@ type Renderer struct {
 	textProcessor     *TextProcessor
 	variableProcessor *VariableProcessor
 	utils             *Utils
+	fonts             *FontRegistry
+	log               *logger.Logger
+	baseCache         map[string]*baseImage
+	seed              int64
+	rng               *rand.Rand
+	expandEnv         bool
+	strictImages      bool
+	missingEnv        map[string]bool // Undefined env vars already warned about
+	placeholder       placeholderLook
+	embedSRGB         bool
+	onlyLayers        []string                                    // Render only these layers (all when empty)
+	skipLayers        []string                                    // Never render these layers
+	filtered          map[*templates.Template]*templates.Template // Templates with the layer filter applied
+	spilled           map[string][]FormattedLine                  // Overflowed lines waiting for their overflow_into layer
+	scale             float64                                     // Render size relative to the template's dimensions
+	scaled            map[*templates.Template]*templates.Template // Templates scaled by scale
+	svg               *svgRecorder                                // Records SVG elements instead of drawing, during RenderSVG
+	layerCache        *layerCache                                 // Drawn layers reused across cards (nil when disabled)
 }
 
 // NewRenderer creates a new renderer instance
 func NewRenderer() *Renderer {
+	fonts := NewFontRegistry()
+
 	return &Renderer{
 		imageProcessor:    NewImageProcessor(),
-		textProcessor:     NewTextProcessor(),
+		textProcessor:     NewTextProcessor(fonts),
 		variableProcessor: NewVariableProcessor(),
 		utils:             NewUtils(),
+		fonts:             fonts,
+		log:               logger.New(logger.LevelInfo),
+		baseCache:         make(map[string]*baseImage),
+		rng:               rand.New(rand.NewSource(0)),
+		missingEnv:        make(map[string]bool),
+		placeholder:       defaultPlaceholderLook,
+		scale:             1,
 	}
 }
 
+// SetStrictImages makes image layers fail when no source loads instead of
+// drawing a placeholder
+func (r *Renderer) SetStrictImages(strict bool) {
+	r.strictImages = strict
+}
+
+// SetVariables sets template variables that override every card's own values
+func (r *Renderer) SetVariables(vars map[string]string) {
+	r.variableProcessor.SetOverrides(vars)
+}
+
+// SetSeed seeds all randomized rendering so output is reproducible per seed
+func (r *Renderer) SetSeed(seed int64) {
+	r.seed = seed
+	r.rng = rand.New(rand.NewSource(seed))
+}
+
+// Rand returns the renderer's seeded random source
+func (r *Renderer) Rand() *rand.Rand {
+	return r.rng
+}
+
+// SetLogger sets the logger used for render diagnostics
+func (r *Renderer) SetLogger(log *logger.Logger) {
+	r.log = log
+	r.imageProcessor.log = log
+}
+
+// RegisterFont registers TTF/OTF data under a font family name so that
+// templates can reference it via font.family
+func (r *Renderer) RegisterFont(name string, data []byte) error {
+	return r.fonts.Register(name, data)
+}
+
+// LoadFontDirectory registers all fonts found in a directory
+func (r *Renderer) LoadFontDirectory(dir string) error {
+	return r.fonts.LoadDirectory(dir)
+}
+
+// Fonts returns every registered font face; unknown families use the Go fonts
+func (r *Renderer) Fonts() []FontFace {
+	return r.fonts.Faces()
+}
+
+// ImageCacheStats returns how many image loads were served from the cache
+func (r *Renderer) ImageCacheStats() types.CacheStats {
+	return r.imageProcessor.cacheStats
+}
+
+// MatchesCondition evaluates a layer-style condition against a card's
+// template variables
+func (r *Renderer) MatchesCondition(card *metadata.Card, template *templates.Template, condition string) bool {
+	return r.utils.EvaluateCondition(condition, r.variableProcessor.BuildTemplateVariables(card, template))
+}
+
 // RenderCard generates a PNG image from a card and template
 func (r *Renderer) RenderCard(card *metadata.Card, template *templates.Template, outputPath string) error {
-	// Create drawing context
-	dc := gg.NewContext(template.Dimensions.Width, template.Dimensions.Height)
+	img, err := r.RenderImage(card, template)
+	if err != nil {
+		return err
+	}
+
+	return r.SaveImage(img, outputPath)
+}
+
+// RenderCardToWriter renders a card and writes it as PNG to w, e.g. stdout
+// for editor previews that shouldn't go through a temp file
+func (r *Renderer) RenderCardToWriter(card *metadata.Card, template *templates.Template, w io.Writer) error {
+	img, err := r.RenderImage(card, template)
+	if err != nil {
+		return err
+	}
+
+	return r.EncodeImage(img, w)
+}
+
+// EncodeImage writes a rendered image as PNG to w
+func (r *Renderer) EncodeImage(img image.Image, w io.Writer) error {
+	if r.embedSRGB {
+		return encodeSRGBPNG(w, img)
+	}
+	return png.Encode(w, img)
+}
+
+// SaveImage writes a rendered image as PNG
+func (r *Renderer) SaveImage(img image.Image, outputPath string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("error saving image to %s: %v", outputPath, err)
+	}
+
+	err = r.EncodeImage(img, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error saving image to %s: %v", outputPath, err)
+	}
+	return nil
+}
 
-	// Set background to white
-	dc.SetColor(color.White)
-	dc.Clear()
+// SaveThumbnail downscales a rendered image to width, preserving aspect ratio,
+// and writes it as PNG
+func (r *Renderer) SaveThumbnail(img image.Image, width int, outputPath string) error {
+	if width <= 0 {
+		return fmt.Errorf("invalid thumbnail width %d", width)
+	}
+	return r.SaveImage(r.scaleToWidth(img, width), outputPath)
+}
+
+// LayerResult describes where a layer landed on a rendered card, for tools
+// that map clicks on a preview back to layers
+type LayerResult struct {
+	Name   string
+	Region templates.Region // Final region, after scaling
+	Drawn  bool
+	Reason string // What the layer drew, or why it was skipped
+	Text   string // Resolved content of text layers
+}
+
+// RenderImage renders a card into an in-memory image
+func (r *Renderer) RenderImage(card *metadata.Card, template *templates.Template) (image.Image, error) {
+	img, _, err := r.RenderLayout(card, template)
+	return img, err
+}
+
+// RenderLayout renders a card like RenderImage and also reports what each
+// layer drew. Layers left out by the layer filter aren't reported.
+func (r *Renderer) RenderLayout(card *metadata.Card, template *templates.Template) (image.Image, []LayerResult, error) {
+	if err := template.ValidateGeometry(); err != nil {
+		return nil, nil, err
+	}
+	template = r.scaleTemplate(r.filterLayers(template))
 
 	// Process template variables for this card
 	templateVars := r.variableProcessor.BuildTemplateVariables(card, template)
+	r.spilled = make(map[string][]FormattedLine)
+
+	// Start from a copy of the template's cached static background
+	base, err := r.baseFor(template, templateVars)
+	if err != nil {
+		return nil, nil, err
+	}
+	dc := gg.NewContextForImage(base.img)
+	if base.layers > 0 {
+		r.log.Debugf("Layers 1-%d: drawn from the cached static background", base.layers)
+	}
+	results := make([]LayerResult, 0, len(template.Layers))
+	for _, result := range base.results {
+		result.Reason += " (cached static background)"
+		results = append(results, result)
+	}
 
-	// Render each layer in order
-	for _, layer := range template.Layers {
-		if err := r.renderLayer(dc, layer, templateVars, template); err != nil {
-			return fmt.Errorf("error rendering layer '%s': %v", layer.Name, err)
+	// Render each remaining layer in order
+	for _, layer := range template.Layers[base.layers:] {
+		result, err := r.renderLayer(dc, layer, templateVars, template)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error rendering layer '%s': %v", layer.Name, err)
 		}
+		results = append(results, result)
 	}
 
-	// Save the image
-	if err := dc.SavePNG(outputPath); err != nil {
-		return fmt.Errorf("error saving image to %s: %v", outputPath, err)
+	// Overflow targets that were skipped or filtered out lose the spilled text
+	for target := range r.spilled {
+		r.log.Warnf("Text overflowing into layer %s was not drawn: the layer was skipped or doesn't exist", target)
 	}
 
-	return nil
+	return dc.Image(), results, nil
+}
+
+// traceLength caps how much of a resolved value the verbose layer trace shows
+const traceLength = 60
+
+// layerOutcome is what drawing a layer did: whether it drew anything and a
+// description for the verbose trace
+type layerOutcome struct {
+	drawn bool
+	trace string
+}
+
+// drewLayer describes a layer that was drawn
+func drewLayer(trace string) layerOutcome {
+	return layerOutcome{drawn: true, trace: trace}
+}
+
+// skippedLayer describes a layer that drew nothing, and why
+func skippedLayer(reason string) layerOutcome {
+	return layerOutcome{trace: "skipped, " + reason}
 }
 
-// renderLayer renders a single layer
-func (r *Renderer) renderLayer(dc *gg.Context, layer templates.Layer, vars map[string]string, template *templates.Template) error {
+// renderLayer renders a single layer, logging its outcome in verbose mode
+func (r *Renderer) renderLayer(dc *gg.Context, layer templates.Layer, vars map[string]string, template *templates.Template) (LayerResult, error) {
+	result := LayerResult{Name: layer.Name, Region: layer.Region}
+	if layer.Type == "text" {
+		result.Text = r.variableProcessor.SubstituteVariables(selectContent(layer, vars), vars)
+	}
+
+	// Only the variant matching the card's variant_field value is drawn
+	if !r.variantSelected(layer, template, vars) {
+		result.Reason = fmt.Sprintf("skipped, variant %s doesn't match %s %q", layer.Variant, template.VariantField, vars[template.VariantField])
+		r.log.Debugf("Layer %s (%s): %s", layer.Name, layer.Type, result.Reason)
+		return result, nil
+	}
+
 	// Check condition if present
+	condition := ""
 	if layer.Condition != "" {
+		resolved := truncateLabel(r.variableProcessor.SubstituteVariables(layer.Condition, vars), traceLength)
 		if !r.utils.EvaluateCondition(layer.Condition, vars) {
-			return nil // Skip this layer
+			result.Reason = fmt.Sprintf("skipped, condition %q is false", resolved)
+			r.log.Debugf("Layer %s (%s): %s", layer.Name, layer.Type, result.Reason)
+			return result, nil // Skip this layer
 		}
+		condition = fmt.Sprintf("condition %q passed, ", resolved)
 	}
 
-	switch layer.Type {
-	case "image":
-		return r.renderImageLayer(dc, layer, vars)
-	case "text":
-		return r.renderTextLayer(dc, layer, vars, template)
-	default:
-		return fmt.Errorf("unknown layer type: %s", layer.Type)
+	render := func(dc *gg.Context) (layerOutcome, error) {
+		switch layer.Type {
+		case "image":
+			return r.renderImageLayer(dc, layer, vars, template)
+		case "text":
+			outcome, err := r.renderTextLayer(dc, layer, vars, template)
+			if stack := r.variableProcessor.SubstituteVariables(template.LayerFont(layer).Family, vars); strings.Contains(stack, ",") {
+				outcome.trace += fmt.Sprintf(" in %s from font stack %q", r.fonts.FamilyName(stack), stack)
+			}
+			return outcome, err
+		case "pips":
+			return r.renderPipsLayer(dc, layer, vars)
+		default:
+			return layerOutcome{}, fmt.Errorf("unknown layer type: %s", layer.Type)
+		}
+	}
+
+	// Layers drawn the same way for an earlier card come from the layer cache
+	outcome, cached, err := r.drawCachedLayer(dc, layer, vars, template, render)
+	if !cached && err == nil {
+		outcome, err = render(dc)
+	}
+	if err != nil {
+		return result, err
 	}
+
+	result.Drawn = outcome.drawn
+	result.Reason = condition + outcome.trace
+	r.log.Debugf("Layer %s (%s): %s", layer.Name, layer.Type, result.Reason)
+	return result, nil
 }
 
-// renderImageLayer renders an image layer
-func (r *Renderer) renderImageLayer(dc *gg.Context, layer templates.Layer, vars map[string]string) error {
-	// Resolve image source
-	imagePath := r.variableProcessor.SubstituteVariables(layer.Source, vars)
+// renderImageLayer renders an image layer and describes what it drew
+func (r *Renderer) renderImageLayer(dc *gg.Context, layer templates.Layer, vars map[string]string, template *templates.Template) (layerOutcome, error) {
+	// Candidate sources in priority order: source, sources..., fallback
+	candidates := make([]string, 0, len(layer.Sources)+2)
+	candidates = append(candidates, layer.Source)
+	candidates = append(candidates, layer.Sources...)
+	candidates = append(candidates, layer.Fallback)
+	candidates = expandIconDirs(candidates, template.IconDirs)
 
-	if imagePath == "" {
-		// Try fallback
-		if layer.Fallback != "" {
-			imagePath = r.variableProcessor.SubstituteVariables(layer.Fallback, vars)
-		}
-		if imagePath == "" {
-			return fmt.Errorf("no image source for layer %s", layer.Name)
+	var img image.Image
+	var primaryPath, loadedPath string
+
+	// A sprite sheet cell comes first; the other sources act as fallbacks
+	if layer.SourceSheet != "" {
+		cell, cellPath, err := r.loadSheetCell(layer, vars)
+		primaryPath = cellPath
+		if err != nil {
+			r.log.Debugf("Layer %s: sheet cell %s unavailable: %v", layer.Name, cellPath, err)
+		} else {
+			img, loadedPath = cell, cellPath
 		}
 	}
 
-	// Load image (with caching)
-	img, err := r.imageProcessor.LoadImage(imagePath)
-	if err != nil {
-		// Try fallback if main source fails
-		if layer.Fallback != "" && imagePath != r.variableProcessor.SubstituteVariables(layer.Fallback, vars) {
-			fallbackPath := r.variableProcessor.SubstituteVariables(layer.Fallback, vars)
-			img, err = r.imageProcessor.LoadImage(fallbackPath)
+	for _, candidate := range candidates {
+		if img != nil {
+			break
+		}
+
+		imagePath := r.variableProcessor.SubstituteVariables(r.expandEnvironment(candidate), vars)
+		if imagePath == "" {
+			continue
+		}
+		if primaryPath == "" {
+			primaryPath = imagePath
 		}
+
+		// Load image (with caching); the first source that loads wins
+		loaded, err := r.imageProcessor.LoadImage(imagePath)
 		if err != nil {
-			// Create a placeholder rectangle instead of failing
-			r.imageProcessor.RenderPlaceholder(dc, layer, fmt.Sprintf("Missing: %s", filepath.Base(imagePath)))
-			return nil
+			r.log.Debugf("Layer %s: source %s unavailable: %v", layer.Name, imagePath, err)
+			continue
 		}
+
+		img, loadedPath = loaded, imagePath
+	}
+
+	if primaryPath == "" {
+		return layerOutcome{}, fmt.Errorf("no image source for layer %s", layer.Name)
+	}
+
+	if img == nil {
+		if r.strictImages {
+			return layerOutcome{}, fmt.Errorf("no image source could be loaded (first tried %s)", primaryPath)
+		}
+
+		// Create a placeholder rectangle instead of failing
+		r.drawPlaceholder(dc, layer, primaryPath)
+		return drewLayer(fmt.Sprintf("drew placeholder, no source loaded (first tried %s)", truncateLabel(primaryPath, traceLength))), nil
 	}
 
 	// Draw image fitted to the specified region
@@ -114,47 +380,185 @@ func (r *Renderer) renderImageLayer(dc *gg.Context, layer templates.Layer, vars
 	if fitMode == "" {
 		fitMode = "fill" // Final default
 	}
+
+	// Trim padding first so fitting and tone adjustment see the cropped art
+	cacheKey := loadedPath
+	if layer.AutoTrim {
+		var background color.Color
+		if trimColor := r.variableProcessor.SubstituteVariables(layer.TrimColor, vars); trimColor != "" {
+			parsed, err := r.utils.ParseColor(trimColor)
+			if err != nil {
+				return layerOutcome{}, fmt.Errorf("invalid trim_color %q: %v", trimColor, err)
+			}
+			background = parsed
+		}
+		img = r.imageProcessor.TrimImage(loadedPath, img, background)
+		cacheKey = trimKey(loadedPath, background)
+	}
+
+	img = r.imageProcessor.AdjustImage(cacheKey, img, ToneAdjustment{
+		Brightness: valueOr(layer.Brightness, 1),
+		Contrast:   valueOr(layer.Contrast, 1),
+		Saturation: valueOr(layer.Saturation, 1),
+	})
 	fittedImg := r.imageProcessor.CreateFittedImage(img, layer.Region, fitMode)
-	dc.DrawImageAnchored(fittedImg, layer.Region.X+layer.Region.Width/2, layer.Region.Y+layer.Region.Height/2, 0.5, 0.5)
+	if err := r.drawImageCentered(dc, fittedImg, layer.Region.X+layer.Region.Width/2, layer.Region.Y+layer.Region.Height/2); err != nil {
+		return layerOutcome{}, err
+	}
+
+	return drewLayer(fmt.Sprintf("drew %s (%s)", truncateLabel(loadedPath, traceLength), fitMode)), nil
+}
 
+// drawImageCentered draws an image centered on cx, cy, or records it while
+// rendering SVG
+func (r *Renderer) drawImageCentered(dc *gg.Context, img image.Image, cx, cy int) error {
+	if r.svg != nil {
+		size := img.Bounds().Size()
+		return r.svg.image(img, cx-size.X/2, cy-size.Y/2)
+	}
+	dc.DrawImageAnchored(img, cx, cy, 0.5, 0.5)
 	return nil
 }
 
-// renderTextLayer renders a text layer
-func (r *Renderer) renderTextLayer(dc *gg.Context, layer templates.Layer, vars map[string]string, template *templates.Template) error {
-	// Get text content
-	content := r.variableProcessor.SubstituteVariables(layer.Content, vars)
-	if content == "" {
-		return nil // Skip empty content
+// expandIconDirs replaces each source that uses {{icon_dir}} with one
+// candidate per icon directory, so overridden icons are found first
+func expandIconDirs(candidates []string, iconDirs []string) []string {
+	if len(iconDirs) == 0 {
+		return candidates
 	}
 
-	// Strip headers if enabled
-	if layer.StripHeaders {
-		content = r.textProcessor.StripMarkdownHeaders(content)
+	expanded := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !strings.Contains(candidate, "{{icon_dir}}") {
+			expanded = append(expanded, candidate)
+			continue
+		}
+		for _, dir := range iconDirs {
+			expanded = append(expanded, strings.ReplaceAll(candidate, "{{icon_dir}}", dir))
+		}
 	}
+	return expanded
+}
 
-	// Process icon replacements if enabled (after variable substitution)
-	if layer.IconReplace {
-		content = r.variableProcessor.ProcessIconReplacements(content, template, vars)
+// renderTextLayer renders a text layer and describes what it drew
+func (r *Renderer) renderTextLayer(dc *gg.Context, layer templates.Layer, vars map[string]string, template *templates.Template) (layerOutcome, error) {
+	// Catch misspelled icon names before they're replaced
+	if layer.IconReplace && !layer.Raw {
+		if err := r.checkIcons(layer, vars, template); err != nil {
+			return layerOutcome{}, err
+		}
 	}
 
-	// Process markdown formatting
-	formattedLines := r.textProcessor.ProcessMarkdown(content)
+	// Get text content
+	content := r.prepareTextContent(layer, vars, template)
+	if layer.Raw {
+		return r.renderRawLayer(dc, layer, content, vars, template)
+	}
+	spilled := r.spilled[layer.Name]
+	delete(r.spilled, layer.Name)
+	if content == "" && len(spilled) == 0 {
+		return skippedLayer("content is empty"), nil
+	}
+	outcome := fmt.Sprintf("drew %q", truncateLabel(content, traceLength))
+	if len(spilled) > 0 {
+		outcome = fmt.Sprintf("drew %d overflowed lines", len(spilled))
+		if content != "" {
+			outcome += fmt.Sprintf(" then %q", truncateLabel(content, traceLength))
+		}
+	}
 
-	// Set up base font
-	baseFont := &templates.Font{Size: 12.0, Color: "#000000"}
-	if layer.Font != nil {
-		baseFont = layer.Font
+	// Process markdown formatting, continuing any text spilled into this layer first
+	formattedLines := spilled
+	if content != "" {
+		formattedLines = append(formattedLines, r.layerLines(layer, content)...)
 	}
 
-	// Calculate text position
-	x := float64(layer.Region.X)
-	y := float64(layer.Region.Y)
+	// Set up base font
+	baseFont := r.layerFont(layer, template, vars)
+
+	// Calculate text position; offsets nudge the text without changing the wrap width
+	x := float64(layer.Region.X) + layer.OffsetX
+	y := float64(layer.Region.Y) + layer.OffsetY
 	w := float64(layer.Region.Width)
 	h := float64(layer.Region.Height)
 
+	// Layers with an overflow_into target stop at their bottom edge and hand
+	// the rest on; others draw everything, even past the region. Continued
+	// text carries on from the top instead of being re-centered.
+	draw := func(target *gg.Context) {
+		var rest []FormattedLine
+		switch {
+		case layer.Columns > 1:
+			rest = r.textProcessor.drawColumns(target, formattedLines, layer, x, y, h, baseFont, vars, layer.OverflowInto != "")
+		case len(spilled) > 0:
+			rest = r.textProcessor.drawFromTop(target, formattedLines, x, y, w, h, layer.Align, layer.BreakMode, baseFont, vars, layer.OverflowInto != "")
+		case layer.OverflowInto != "":
+			rest = r.textProcessor.DrawFormattedText(target, formattedLines, x, y, w, h, layer.Align, layer.BreakMode, baseFont, vars)
+		default:
+			r.textProcessor.drawFormattedText(target, formattedLines, x, y, w, h, layer.Align, layer.BreakMode, baseFont, vars)
+		}
+		if len(rest) > 0 {
+			r.spilled[layer.OverflowInto] = rest
+			outcome += fmt.Sprintf(", %d lines overflowed into %s", len(rest), layer.OverflowInto)
+		}
+	}
+
+	// Gradient and texture fills paint through the text's shape instead of its color
+	if (baseFont.FillGradient != nil || baseFont.FillImage != "") && r.svg == nil {
+		textDC := gg.NewContext(dc.Width(), dc.Height())
+		draw(textDC)
+		return drewLayer(outcome), r.fillThroughMask(dc, textDC.AsMask(), layer, baseFont, vars)
+	}
+
 	// Render formatted text
-	r.textProcessor.DrawFormattedText(dc, formattedLines, x, y, w, h, layer.Align, baseFont, vars)
+	draw(dc)
 
-	return nil
+	return drewLayer(outcome), nil
+}
+
+// layerLines splits resolved text content into formatted lines, applying the
+// layer's first-line indent to every paragraph
+func (r *Renderer) layerLines(layer templates.Layer, content string) []FormattedLine {
+	lines := r.textProcessor.ProcessMarkdown(content)
+	if layer.FirstLineIndent != 0 {
+		for i := range lines {
+			if lines[i].Type == "normal" {
+				lines[i].FirstIndent = layer.FirstLineIndent
+			}
+		}
+	}
+	return lines
+}
+
+// prepareTextContent resolves a text layer's content ready for markdown processing
+func (r *Renderer) prepareTextContent(layer templates.Layer, vars map[string]string, template *templates.Template) string {
+	content := r.variableProcessor.SubstituteVariables(selectContent(layer, vars), vars)
+	if content == "" || layer.Raw {
+		return content // Raw layers draw the substituted text verbatim
+	}
+
+	// Honor explicit line breaks (e.g. "\n" in a title)
+	content = r.textProcessor.NormalizeLineBreaks(content)
+
+	// Strip headers if enabled
+	if layer.StripHeaders {
+		content = r.textProcessor.StripMarkdownHeaders(content)
+	}
+
+	// Bold ability keywords leading a rules line
+	if layer.BoldKeywords {
+		content = r.textProcessor.BoldKeywords(content, template.Keywords)
+	}
+
+	// Italicize (reminder text)
+	if layer.ReminderItalic {
+		content = r.textProcessor.ItalicizeReminderText(content)
+	}
+
+	// Process icon replacements if enabled (after variable substitution)
+	if layer.IconReplace {
+		content = r.variableProcessor.ProcessIconReplacements(content, template, vars)
+	}
+
+	return content
 }
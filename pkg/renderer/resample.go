@@ -0,0 +1,95 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// sharpenAmount is the strength of the unsharp mask applied after downscaling
+const sharpenAmount = 0.5
+
+// resamplers maps resample quality names to interpolators, fastest first
+var resamplers = map[string]xdraw.Interpolator{
+	"nearest":    xdraw.NearestNeighbor,
+	"bilinear":   xdraw.BiLinear,
+	"catmullrom": xdraw.CatmullRom,
+}
+
+// SetResampling sets how image layers are scaled to fit their regions:
+// "nearest" (fast previews), "bilinear" (default) or "catmullrom" (sharpest).
+// With sharpen, downscaled images also get a mild unsharp mask.
+func (r *Renderer) SetResampling(quality string, sharpen bool) error {
+	if quality == "" {
+		quality = "bilinear"
+	}
+	interpolator, exists := resamplers[strings.ToLower(quality)]
+	if !exists {
+		return fmt.Errorf("unknown resample quality '%s' (use nearest, bilinear or catmullrom)", quality)
+	}
+
+	r.imageProcessor.interpolator = interpolator
+	r.imageProcessor.sharpen = sharpen
+	return nil
+}
+
+// drawScaled draws img onto dst scaled by scale, with its top-left corner at
+// (x, y) in unscaled units, the same placement as gg's Scale + DrawImage
+func (ip *ImageProcessor) drawScaled(dst *image.RGBA, img image.Image, scale float64, x, y int) {
+	transform := [6]float64{scale, 0, scale * float64(x), 0, scale, scale * float64(y)}
+	ip.interpolator.Transform(dst, transform, img, img.Bounds(), xdraw.Over, nil)
+
+	if ip.sharpen && scale < 1 {
+		unsharpMask(dst, sharpenAmount)
+	}
+}
+
+// unsharpMask sharpens img in place by adding back its difference from a 3x3 box blur
+func unsharpMask(img *image.RGBA, amount float64) {
+	bounds := img.Bounds()
+	source := make([]uint8, len(img.Pix))
+	copy(source, img.Pix)
+
+	at := func(x, y, channel int) float64 {
+		x = clampInt(x, bounds.Min.X, bounds.Max.X-1)
+		y = clampInt(y, bounds.Min.Y, bounds.Max.Y-1)
+		return float64(source[img.PixOffset(x, y)+channel])
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			offset := img.PixOffset(x, y)
+			alpha := float64(source[offset+3])
+			for channel := 0; channel < 3; channel++ {
+				blur := 0.0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						blur += at(x+dx, y+dy, channel)
+					}
+				}
+				original := float64(source[offset+channel])
+				sharpened := original + amount*(original-blur/9)
+				// Premultiplied color can't exceed alpha
+				if sharpened > alpha {
+					sharpened = alpha
+				} else if sharpened < 0 {
+					sharpened = 0
+				}
+				img.Pix[offset+channel] = uint8(sharpened + 0.5)
+			}
+		}
+	}
+}
+
+// clampInt limits value to [min, max]
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,34 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// SetScale renders every card at factor times its template's dimensions,
+// e.g. 2 for @2x exports. Regions, font sizes and line widths scale with it.
+func (r *Renderer) SetScale(factor float64) error {
+	if factor <= 0 {
+		return fmt.Errorf("scale must be positive, got %g", factor)
+	}
+	r.scale = factor
+	r.scaled = make(map[*templates.Template]*templates.Template)
+	r.textProcessor.lineWidth = factor
+	return nil
+}
+
+// scaleTemplate returns the template scaled by the render scale. The scaled
+// copy is reused per template so its cached background stays valid.
+func (r *Renderer) scaleTemplate(template *templates.Template) *templates.Template {
+	if r.scale == 1 {
+		return template
+	}
+	if scaled, exists := r.scaled[template]; exists {
+		return scaled
+	}
+
+	scaled := template.Scaled(r.scale)
+	r.scaled[template] = scaled
+	return scaled
+}
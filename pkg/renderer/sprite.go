@@ -0,0 +1,69 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strconv"
+	"strings"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// loadSheetCell loads a layer's sprite sheet and crops its sheet_index cell.
+// The returned path names the cell (e.g. symbols.png#12) for caching and logs.
+func (r *Renderer) loadSheetCell(layer templates.Layer, vars map[string]string) (image.Image, string, error) {
+	sheetPath := r.variableProcessor.SubstituteVariables(r.expandEnvironment(layer.SourceSheet), vars)
+	indexText := strings.TrimSpace(r.variableProcessor.SubstituteVariables(layer.SheetIndex, vars))
+	cellPath := sheetPath + "#" + indexText
+
+	index, err := strconv.Atoi(indexText)
+	if err != nil {
+		return nil, cellPath, fmt.Errorf("sheet index '%s' is not a whole number", indexText)
+	}
+
+	sheet, err := r.imageProcessor.LoadImage(sheetPath)
+	if err != nil {
+		return nil, cellPath, err
+	}
+
+	cell, err := r.imageProcessor.SheetCell(sheetPath, sheet, layer.SheetCols, layer.SheetRows, index)
+	if err != nil {
+		return nil, cellPath, err
+	}
+	return cell, cellPath, nil
+}
+
+// SheetCell crops cell index from a sheet divided into cols x rows equal
+// cells, counting from 0 left to right then top to bottom. Zero cols or rows
+// count as one. Cells are cached by sheet path, grid and index.
+func (ip *ImageProcessor) SheetCell(path string, sheet image.Image, cols, rows, index int) (image.Image, error) {
+	if cols <= 0 {
+		cols = 1
+	}
+	if rows <= 0 {
+		rows = 1
+	}
+	if index < 0 || index >= cols*rows {
+		return nil, fmt.Errorf("sheet index %d is outside the %dx%d grid", index, cols, rows)
+	}
+
+	key := fmt.Sprintf("%s#%dx%d:%d", path, cols, rows, index)
+	if cell, exists := ip.cache[key]; exists {
+		return cell, nil
+	}
+
+	bounds := sheet.Bounds()
+	cellWidth, cellHeight := bounds.Dx()/cols, bounds.Dy()/rows
+	if cellWidth == 0 || cellHeight == 0 {
+		return nil, fmt.Errorf("sheet %s is too small for a %dx%d grid", path, cols, rows)
+	}
+
+	// Copy the cell to its own image so fitting sees an origin at 0,0
+	origin := image.Pt(bounds.Min.X+(index%cols)*cellWidth, bounds.Min.Y+(index/cols)*cellHeight)
+	cell := image.NewNRGBA(image.Rect(0, 0, cellWidth, cellHeight))
+	draw.Draw(cell, cell.Bounds(), sheet, origin, draw.Src)
+
+	ip.cache[key] = cell
+	return cell, nil
+}
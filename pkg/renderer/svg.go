@@ -0,0 +1,196 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// svgRecorder collects the SVG elements for a card in draw order. While it's
+// attached, the renderer's drawing helpers record elements here instead of
+// painting pixels, so the SVG uses exactly the raster layout.
+type svgRecorder struct {
+	fonts    *FontRegistry
+	body     strings.Builder
+	defs     strings.Builder
+	embedded map[*truetype.Font]string // CSS font family of each embedded font
+}
+
+// newSVGRecorder creates an empty recorder resolving fonts from fonts
+func newSVGRecorder(fonts *FontRegistry) *svgRecorder {
+	return &svgRecorder{
+		fonts:    fonts,
+		embedded: make(map[*truetype.Font]string),
+	}
+}
+
+// RenderSVG renders a card as an SVG document: text as <text> with the fonts
+// embedded, images as embedded PNGs and backgrounds, placeholders and pips as
+// shapes. Gradient and texture text fills are drawn in the font's color.
+func (r *Renderer) RenderSVG(card *metadata.Card, template *templates.Template, outputPath string) error {
+	data, err := r.RenderSVGBytes(card, template)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("error saving SVG to %s: %v", outputPath, err)
+	}
+	return nil
+}
+
+// RenderSVGBytes renders a card as an SVG document in memory
+func (r *Renderer) RenderSVGBytes(card *metadata.Card, template *templates.Template) ([]byte, error) {
+	if err := template.ValidateGeometry(); err != nil {
+		return nil, err
+	}
+	template = r.scaleTemplate(r.filterLayers(template))
+
+	templateVars := r.variableProcessor.BuildTemplateVariables(card, template)
+	r.spilled = make(map[string][]FormattedLine)
+
+	r.svg = newSVGRecorder(r.fonts)
+	r.textProcessor.recorder = r.svg
+	defer func() {
+		r.svg = nil
+		r.textProcessor.recorder = nil
+	}()
+
+	// The context is only used to measure text; nothing is painted into it
+	dc := gg.NewContext(template.Dimensions.Width, template.Dimensions.Height)
+	background := r.variableProcessor.SubstituteVariables(template.Background, templateVars)
+	if err := r.paintBackground(dc, background); err != nil {
+		return nil, err
+	}
+
+	for _, layer := range template.Layers {
+		if _, err := r.renderLayer(dc, layer, templateVars, template); err != nil {
+			return nil, fmt.Errorf("error rendering layer '%s': %v", layer.Name, err)
+		}
+	}
+
+	for target := range r.spilled {
+		r.log.Warnf("Text overflowing into layer %s was not drawn: the layer was skipped or doesn't exist", target)
+	}
+
+	return r.svg.document(template.Dimensions.Width, template.Dimensions.Height), nil
+}
+
+// document wraps the recorded elements in an <svg> root of the given size
+func (s *svgRecorder) document(width, height int) []byte {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	if s.defs.Len() > 0 {
+		fmt.Fprintf(&out, "<defs><style>\n%s</style></defs>\n", s.defs.String())
+	}
+	out.WriteString(s.body.String())
+	out.WriteString("</svg>\n")
+	return out.Bytes()
+}
+
+// rect records a rectangle; a nil stroke draws no outline
+func (s *svgRecorder) rect(x, y, w, h float64, fill, stroke color.Color, strokeWidth float64) {
+	fmt.Fprintf(&s.body, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"%s%s/>\n",
+		svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), svgPaint("fill", fill), svgStroke(stroke, strokeWidth))
+}
+
+// circle records a circle; a nil fill draws only the outline
+func (s *svgRecorder) circle(cx, cy, radius float64, fill, stroke color.Color, strokeWidth float64) {
+	fmt.Fprintf(&s.body, "<circle cx=\"%s\" cy=\"%s\" r=\"%s\"%s%s/>\n",
+		svgNumber(cx), svgNumber(cy), svgNumber(radius), svgPaint("fill", fill), svgStroke(stroke, strokeWidth))
+}
+
+// line records a straight line
+func (s *svgRecorder) line(x1, y1, x2, y2 float64, stroke color.Color, strokeWidth float64) {
+	fmt.Fprintf(&s.body, "<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\"%s/>\n",
+		svgNumber(x1), svgNumber(y1), svgNumber(x2), svgNumber(y2), svgStroke(stroke, strokeWidth))
+}
+
+// image records an image embedded as a PNG with its top-left corner at x, y
+func (s *svgRecorder) image(img image.Image, x, y int) error {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return fmt.Errorf("failed to encode image for SVG: %v", err)
+	}
+
+	bounds := img.Bounds()
+	fmt.Fprintf(&s.body, "<image x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" preserveAspectRatio=\"none\" href=\"data:image/png;base64,%s\"/>\n",
+		x, y, bounds.Dx(), bounds.Dy(), base64.StdEncoding.EncodeToString(encoded.Bytes()))
+	return nil
+}
+
+// text records a run of text with its baseline starting at x, y. The font
+// it resolves to is embedded; italics the font lacks are slanted by the viewer.
+func (s *svgRecorder) text(content string, x, y float64, font fontState) {
+	f, synthItalic := s.fonts.Font(font.family, font.bold, font.italic)
+	style := ""
+	if synthItalic {
+		style = " font-style=\"italic\""
+	}
+
+	fmt.Fprintf(&s.body, "<text x=\"%s\" y=\"%s\" font-family=\"%s, '%s', sans-serif\" font-size=\"%s\"%s%s xml:space=\"preserve\">%s</text>\n",
+		svgNumber(x), svgNumber(y), s.embedFont(f), html.EscapeString(s.fonts.FamilyName(font.family)),
+		svgNumber(font.size), style, svgPaint("fill", font.color), html.EscapeString(content))
+}
+
+// label records placeholder text centered on cx, cy in the viewer's monospace font
+func (s *svgRecorder) label(content string, cx, cy float64, fill color.Color) {
+	fmt.Fprintf(&s.body, "<text x=\"%s\" y=\"%s\" font-family=\"monospace\" font-size=\"13\" text-anchor=\"middle\" dominant-baseline=\"middle\"%s>%s</text>\n",
+		svgNumber(cx), svgNumber(cy), svgPaint("fill", fill), html.EscapeString(content))
+}
+
+// embedFont adds an @font-face for a font the first time it's used and
+// returns its CSS family name
+func (s *svgRecorder) embedFont(f *truetype.Font) string {
+	if name, exists := s.embedded[f]; exists {
+		return name
+	}
+
+	name := fmt.Sprintf("card-font-%d", len(s.embedded)+1)
+	s.embedded[f] = name
+	if data := s.fonts.data[f]; len(data) > 0 {
+		fmt.Fprintf(&s.defs, "@font-face { font-family: %s; src: url(data:font/ttf;base64,%s); }\n",
+			name, base64.StdEncoding.EncodeToString(data))
+	}
+	return name
+}
+
+// svgNumber formats a coordinate compactly
+func svgNumber(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+// svgPaint formats a fill or stroke attribute, with opacity for translucent
+// colors; a nil color paints nothing
+func svgPaint(attribute string, c color.Color) string {
+	if c == nil {
+		return fmt.Sprintf(" %s=\"none\"", attribute)
+	}
+
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	paint := fmt.Sprintf(" %s=\"#%02x%02x%02x\"", attribute, n.R, n.G, n.B)
+	if n.A < 255 {
+		paint += fmt.Sprintf(" %s-opacity=\"%s\"", attribute, svgNumber(float64(n.A)/255))
+	}
+	return paint
+}
+
+// svgStroke formats stroke attributes; a nil color draws no outline
+func svgStroke(c color.Color, width float64) string {
+	if c == nil {
+		return ""
+	}
+	return svgPaint("stroke", c) + fmt.Sprintf(" stroke-width=\"%s\"", svgNumber(width))
+}
@@ -2,24 +2,38 @@ package renderer
 
 import (
 	"image/color"
+	"math"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/fogleman/gg"
-	"github.com/golang/freetype/truetype"
-	"golang.org/x/image/font/gofont/gobold"
-	"golang.org/x/image/font/gofont/goitalic"
-	"golang.org/x/image/font/gofont/goregular"
 
 	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
 )
 
+// largeTextScale is the size scale applied to ^^larger^^ text
+const largeTextScale = 1.5
+
+// Superscript/subscript runs render smaller with a shifted baseline
+const (
+	scriptScale      = 0.6
+	superscriptShift = 0.4  // Raise, as a fraction of the surrounding text size
+	subscriptShift   = 0.15 // Lower, as a fraction of the surrounding text size
+)
+
+// lineBreakReplacer converts explicit line break markers to newlines
+var lineBreakReplacer = strings.NewReplacer(`\n`, "\n", "<br>", "\n", "<br/>", "\n", "<br />", "\n")
+
 // TextStyle represents text formatting options
 type TextStyle struct {
 	Bold   bool
 	Italic bool
-	Size   float64
+	Size   float64 // Scale relative to the base font size (0 = base size)
 	Color  color.Color
+
+	Superscript bool
+	Subscript   bool
 }
 
 // FormattedText represents a piece of text with styling
@@ -33,17 +47,26 @@ type FormattedLine struct {
 	Segments []FormattedText
 	Type     string // "normal", "header", "hr" (horizontal rule)
 	Level    int    // header level (1-6)
+	Indent   int    // leading spaces (tabs count as 4) preserved from the source
+
+	FirstIndent float64 // Extra indent of the first wrapped line, in pixels (negative hangs)
 }
 
 // TextProcessor handles all text processing operations
 type TextProcessor struct {
-	utils *Utils
+	utils     *Utils
+	fonts     *FontRegistry
+	lineWidth float64      // Width of horizontal rules, scaled with the render
+	font      fontState    // Font last set with setFont
+	recorder  *svgRecorder // Records text as SVG instead of drawing it, when set
 }
 
-// NewTextProcessor creates a new text processor
-func NewTextProcessor() *TextProcessor {
+// NewTextProcessor creates a new text processor that resolves fonts from the given registry
+func NewTextProcessor(fonts *FontRegistry) *TextProcessor {
 	return &TextProcessor{
-		utils: NewUtils(),
+		utils:     NewUtils(),
+		fonts:     fonts,
+		lineWidth: 1,
 	}
 }
 
@@ -53,7 +76,9 @@ func (tp *TextProcessor) ProcessMarkdown(content string) []FormattedLine {
 	var formattedLines []FormattedLine
 
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
+		line = strings.TrimRight(line, " \t\r")
+		indent := leadingIndent(line)
+		line = strings.TrimLeft(line, " \t")
 
 		// Skip empty lines but preserve them for spacing
 		if line == "" {
@@ -101,6 +126,7 @@ func (tp *TextProcessor) ProcessMarkdown(content string) []FormattedLine {
 		formattedLines = append(formattedLines, FormattedLine{
 			Segments: tp.parseInlineFormatting(line),
 			Type:     "normal",
+			Indent:   indent,
 		})
 	}
 
@@ -149,6 +175,25 @@ func (tp *TextProcessor) parseFormattingRecursive(text string) []FormattedText {
 		}
 	}
 
+	// Look for ^^larger^^ text (size hint) or ^superscript^, taking whichever marker comes first
+	if idx := strings.Index(text, "^"); idx != -1 && (pos == -1 || idx < pos) {
+		pos = idx
+		if strings.HasPrefix(text[idx:], "^^") {
+			marker = "^^"
+			markerLength = 2
+		} else {
+			marker = "^"
+			markerLength = 1
+		}
+	}
+
+	// Look for ~subscript~
+	if idx := strings.Index(text, "~"); idx != -1 && (pos == -1 || idx < pos) {
+		pos = idx
+		marker = "~"
+		markerLength = 1
+	}
+
 	if pos == -1 {
 		// No formatting found, return as plain text
 		if text != "" {
@@ -184,22 +229,49 @@ func (tp *TextProcessor) parseFormattingRecursive(text string) []FormattedText {
 	// Extract the formatted content
 	formattedContent := remaining[:closePos]
 
-	// Determine the style
-	style := TextStyle{Bold: false, Italic: false}
-	switch marker {
-	case "***":
-		style.Bold = true
-		style.Italic = true
-	case "**":
-		style.Bold = true
-	case "*":
-		style.Italic = true
+	// An empty script run ("~~", "^^" handled above) is literal text
+	if formattedContent == "" && (marker == "^" || marker == "~") {
+		segments = append(segments, FormattedText{Content: marker + marker})
+		if afterMarker := remaining[closePos+markerLength:]; afterMarker != "" {
+			segments = append(segments, tp.parseFormattingRecursive(afterMarker)...)
+		}
+		return segments
+	}
+
+	// Size and script markers may wrap other formatting, so parse their content recursively
+	if marker == "^^" || marker == "^" || marker == "~" {
+		for _, inner := range tp.parseFormattingRecursive(formattedContent) {
+			switch marker {
+			case "^^":
+				inner.Style.Size = largeTextScale
+			case "^":
+				inner.Style.Superscript = true
+			case "~":
+				inner.Style.Subscript = true
+			}
+			segments = append(segments, inner)
+		}
+
+		afterMarker := remaining[closePos+markerLength:]
+		if afterMarker != "" {
+			segments = append(segments, tp.parseFormattingRecursive(afterMarker)...)
+		}
+		return segments
 	}
 
-	segments = append(segments, FormattedText{
-		Content: formattedContent,
-		Style:   style,
-	})
+	// Apply the emphasis to the content, which may itself contain size or script markers
+	for _, inner := range tp.parseFormattingRecursive(formattedContent) {
+		switch marker {
+		case "***":
+			inner.Style.Bold = true
+			inner.Style.Italic = true
+		case "**":
+			inner.Style.Bold = true
+		case "*":
+			inner.Style.Italic = true
+		}
+		segments = append(segments, inner)
+	}
 
 	// Process the rest of the text
 	afterMarker := remaining[closePos+markerLength:]
@@ -210,28 +282,49 @@ func (tp *TextProcessor) parseFormattingRecursive(text string) []FormattedText {
 	return segments
 }
 
-// SeparateFooter separates footer content from main body content
-func (tp *TextProcessor) SeparateFooter(content string) (body string, footer string) {
+// defaultFooterHeaders start the footer when a template has no footer rule
+var defaultFooterHeaders = []string{"## Footer"}
+
+// SeparateFooter separates footer content from main body content at the
+// first of the rule's headers or its marker, or else (with last_block) at
+// the last "---" line. found reports whether a footer section was found.
+func (tp *TextProcessor) SeparateFooter(content string, rule templates.FooterRule) (body string, footer string, found bool) {
 	lines := strings.Split(content, "\n")
 	footerStartIndex := -1
 
-	// Look for "## Footer" header (case insensitive)
+	starts := rule.Headers
+	if len(starts) == 0 && rule.Marker == "" {
+		starts = defaultFooterHeaders
+	}
+	if rule.Marker != "" {
+		starts = append(append([]string{}, starts...), rule.Marker)
+	}
+
+	// Look for the first footer header or marker (case insensitive)
 	for i, line := range lines {
-		trimmed := strings.TrimSpace(strings.ToLower(line))
-		if trimmed == "## footer" {
+		if isFooterStart(line, starts) {
 			footerStartIndex = i
 			break
 		}
 	}
 
+	if footerStartIndex == -1 && rule.LastBlock {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if strings.HasPrefix(strings.TrimSpace(lines[i]), "---") {
+				footerStartIndex = i
+				break
+			}
+		}
+	}
+
 	if footerStartIndex == -1 {
 		// No footer found, return original content as body
-		return content, ""
+		return content, "", false
 	}
 
 	// Split the content
 	bodyLines := lines[:footerStartIndex]
-	footerLines := lines[footerStartIndex+1:] // Skip the "## Footer" line itself
+	footerLines := lines[footerStartIndex+1:] // Skip the header or separator line itself
 
 	// Clean up body (remove trailing empty lines)
 	for len(bodyLines) > 0 && strings.TrimSpace(bodyLines[len(bodyLines)-1]) == "" {
@@ -246,7 +339,25 @@ func (tp *TextProcessor) SeparateFooter(content string) (body string, footer str
 	body = strings.Join(bodyLines, "\n")
 	footer = strings.Join(footerLines, "\n")
 
-	return body, footer
+	return body, footer, true
+}
+
+// isFooterStart reports whether a line is one of the footer start lines,
+// ignoring case and surrounding whitespace
+func isFooterStart(line string, starts []string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, start := range starts {
+		if strings.EqualFold(trimmed, strings.TrimSpace(start)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeLineBreaks turns explicit break markers (a literal "\n" or <br>)
+// into real newlines so single-line fields like titles can wrap manually
+func (tp *TextProcessor) NormalizeLineBreaks(content string) string {
+	return lineBreakReplacer.Replace(content)
 }
 
 // StripMarkdownHeaders removes markdown headers from content
@@ -264,13 +375,8 @@ func (tp *TextProcessor) StripMarkdownHeaders(content string) string {
 	return strings.Join(cleanLines, "\n")
 }
 
-// DrawFormattedText renders formatted markdown text with proper styling
-func (tp *TextProcessor) DrawFormattedText(dc *gg.Context, lines []FormattedLine, x, y, w, h float64, align string, baseFont *templates.Font, vars map[string]string) {
-	if len(lines) == 0 {
-		return
-	}
-
-	// Get base font size
+// resolveFontSize resolves a font's size, which may be a number or a variable template
+func (tp *TextProcessor) resolveFontSize(baseFont *templates.Font, vars map[string]string) float64 {
 	baseSize := 12.0
 	if baseFont.Size != nil {
 		switch s := baseFont.Size.(type) {
@@ -285,63 +391,149 @@ func (tp *TextProcessor) DrawFormattedText(dc *gg.Context, lines []FormattedLine
 			}
 		}
 	}
+	return baseSize
+}
+
+// MeasureFormattedText returns the height formatted text occupies when drawn
+// at width w, from the top of its first line to the bottom of its last, using
+// the font's ascent and descent and wrapping exactly as DrawFormattedText does
+func (tp *TextProcessor) MeasureFormattedText(dc *gg.Context, lines []FormattedLine, w float64, breakMode string, baseFont *templates.Font, vars map[string]string) float64 {
+	baseSize := tp.resolveFontSize(baseFont, vars)
+	family := tp.utils.SubstituteVariables(baseFont.Family, vars)
+
+	top, bottom := textExtent(tp.layoutLines(dc, lines, w, family, baseSize, breakMode))
+	return bottom - top
+}
+
+// DrawFormattedText renders formatted markdown text with proper styling.
+// Text taller than the region is drawn from the region's top down to its
+// bottom edge, and the lines that didn't fit are returned so they can
+// continue elsewhere; nil means everything was drawn.
+func (tp *TextProcessor) DrawFormattedText(dc *gg.Context, lines []FormattedLine, x, y, w, h float64, align, breakMode string, baseFont *templates.Font, vars map[string]string) []FormattedLine {
+	if tp.MeasureFormattedText(dc, lines, w, breakMode, baseFont, vars) <= h {
+		tp.drawFormattedText(dc, lines, x, y, w, h, align, breakMode, baseFont, vars)
+		return nil
+	}
+	return tp.drawFromTop(dc, lines, x, y, w, h, align, breakMode, baseFont, vars, true)
+}
+
+// drawFromTop renders formatted text downward from the region's top instead
+// of centering it. With clip set, drawing stops at the bottom edge and the
+// lines that didn't fit are returned.
+func (tp *TextProcessor) drawFromTop(dc *gg.Context, lines []FormattedLine, x, y, w, h float64, align, breakMode string, baseFont *templates.Font, vars map[string]string, clip bool) []FormattedLine {
+	var rest []FormattedLine
+	if clip {
+		lines, rest = tp.splitFormattedText(dc, lines, w, h, breakMode, baseFont, vars)
+	}
+
+	// The first line's ascenders touch the top of the region
+	baseSize := tp.resolveFontSize(baseFont, vars)
+	family := tp.utils.SubstituteVariables(baseFont.Family, vars)
+	top, _ := textExtent(tp.layoutLines(dc, lines, w, family, baseSize, breakMode))
+	tp.drawLines(dc, lines, x, y-top, w, family, baseSize, tp.baseColor(baseFont, vars), align, breakMode)
+	return rest
+}
+
+// splitFormattedText divides lines into the ones that fit in height h when
+// wrapped at width w and the remainder, measuring as MeasureFormattedText
+// does. A paragraph that straddles the bottom edge is split between its
+// wrapped lines.
+func (tp *TextProcessor) splitFormattedText(dc *gg.Context, lines []FormattedLine, w, h float64, breakMode string, baseFont *templates.Font, vars map[string]string) ([]FormattedLine, []FormattedLine) {
+	baseSize := tp.resolveFontSize(baseFont, vars)
+	family := tp.utils.SubstituteVariables(baseFont.Family, vars)
+
+	placed := tp.layoutLines(dc, lines, w, family, baseSize, breakMode)
+	top, _ := textExtent(placed)
+	for _, p := range placed {
+		if p.bottom-top <= h {
+			continue
+		}
+		if p.wrap == 0 {
+			return lines[:p.index], lines[p.index:]
+		}
+
+		// Split the paragraph before the first wrapped line that doesn't fit
+		line := lines[p.index]
+		indent := tp.indentWidth(dc, line.Indent, family, baseSize)
+		wrappedLines, spaced := tp.wrapFormattedSegments(dc, line.Segments, w-indent, line.FirstIndent, family, baseSize, color.Black, breakMode)
+		head, tail := line, line
+		head.Segments = joinWrappedLines(wrappedLines[:p.wrap], spaced[:p.wrap])
+		tail.Segments = joinWrappedLines(wrappedLines[p.wrap:], spaced[p.wrap:])
+		tail.FirstIndent = 0 // The continuation isn't a new paragraph
+		rest := append([]FormattedLine{tail}, lines[p.index+1:]...)
+		return append(lines[:p.index:p.index], head), rest
+	}
+
+	return lines, nil
+}
 
-	// Get base color
-	var baseColor color.Color = color.Black
+// joinWrappedLines merges wrapped lines back into one run of segments,
+// restoring the spaces dropped at each line break
+func joinWrappedLines(wrappedLines [][]FormattedText, spaced []bool) []FormattedText {
+	var segments []FormattedText
+	for i, wrapped := range wrappedLines {
+		for j, segment := range wrapped {
+			if i > 0 && j == 0 && spaced[i] {
+				segment.Content = " " + segment.Content
+			}
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// baseColor resolves a font's text color, defaulting to black
+func (tp *TextProcessor) baseColor(baseFont *templates.Font, vars map[string]string) color.Color {
 	if baseFont.Color != "" {
 		colorStr := tp.utils.SubstituteVariables(baseFont.Color, vars)
 		if c, err := tp.utils.ParseColor(colorStr); err == nil {
-			baseColor = c
+			return c
 		}
 	}
+	return color.Black
+}
 
-	// Calculate line heights and total height
-	currentY := y
-	lineHeight := baseSize * 1.2
+// drawFormattedText renders formatted text centered vertically in the region
+// by its actual ink extent, drawing every line even if the block is taller
+// than the region; such blocks start at the top and run past the bottom
+func (tp *TextProcessor) drawFormattedText(dc *gg.Context, lines []FormattedLine, x, y, w, h float64, align, breakMode string, baseFont *templates.Font, vars map[string]string) {
+	// Get base font size
+	baseSize := tp.resolveFontSize(baseFont, vars)
 
-	// First pass: calculate total text height for centering
-	totalHeight := 0.0
-	for _, line := range lines {
-		switch line.Type {
-		case "header":
-			// Headers are larger
-			headerSize := baseSize * (2.0 - float64(line.Level)*0.2) // h1=1.8x, h2=1.6x, etc.
-			totalHeight += headerSize * 1.4
-		case "hr":
-			totalHeight += baseSize * 0.5 // Horizontal rule takes less space
-		case "normal":
-			if len(line.Segments) == 0 {
-				totalHeight += lineHeight * 0.5 // Empty line
-			} else {
-				totalHeight += lineHeight
-			}
-		}
+	// Resolve font family (may be a style token)
+	family := tp.utils.SubstituteVariables(baseFont.Family, vars)
+
+	placed := tp.layoutLines(dc, lines, w, family, baseSize, breakMode)
+	if len(placed) == 0 {
+		return // Nothing but blank lines
 	}
 
-	// Center the text block vertically
-	startY := y + (h-totalHeight)/2
+	// Center the block between its first line's ascent and last line's descent
+	top, bottom := textExtent(placed)
+	startY := y + math.Max(0, (h-(bottom-top))/2) - top
 
-	// Second pass: render the text
-	currentY = startY
+	tp.drawLines(dc, lines, x, startY, w, family, baseSize, tp.baseColor(baseFont, vars), align, breakMode)
+}
+
+// drawLines renders formatted lines downward from the baseline at startY
+func (tp *TextProcessor) drawLines(dc *gg.Context, lines []FormattedLine, x, startY, w float64, family string, baseSize float64, baseColor color.Color, align, breakMode string) {
+	lineHeight := baseSize * 1.2
+	currentY := startY
 	for _, line := range lines {
 		switch line.Type {
 		case "header":
 			// Render header with larger font
 			headerSize := baseSize * (2.0 - float64(line.Level)*0.2)
-			tp.setFont(dc, headerSize, true, false, baseColor)
+			tp.setFont(dc, family, headerSize, true, false, baseColor)
 
 			// Render header segments
 			lineText := tp.combineSegments(line.Segments)
-			tp.drawSingleLine(dc, lineText, x, currentY, w, align)
-			currentY += headerSize * 1.4
+			currentY = tp.drawSingleLine(dc, lineText, x, currentY, w, headerSize*1.4, align)
 
 		case "hr":
 			// Draw horizontal rule
-			dc.SetColor(color.RGBA{128, 128, 128, 255})
-			dc.SetLineWidth(1)
 			ruleY := currentY + baseSize*0.25
-			dc.DrawLine(x+w*0.1, ruleY, x+w*0.9, ruleY)
-			dc.Stroke()
+			tp.drawRule(dc, x+w*0.1, x+w*0.9, ruleY)
 			currentY += baseSize * 0.5
 
 		case "normal":
@@ -349,118 +541,126 @@ func (tp *TextProcessor) DrawFormattedText(dc *gg.Context, lines []FormattedLine
 				// Empty line - just add spacing
 				currentY += lineHeight * 0.5
 			} else {
-				// Render formatted segments in this line
-				currentY = tp.drawFormattedLine(dc, line.Segments, x, currentY, w, baseSize, baseColor, align)
+				// Render formatted segments in this line, shifted right by its indentation
+				indent := tp.indentWidth(dc, line.Indent, family, baseSize)
+				currentY = tp.drawFormattedLine(dc, line.Segments, x+indent, currentY, w-indent, line.FirstIndent, family, baseSize, baseColor, align, breakMode)
 			}
 		}
 	}
 }
 
-// drawFormattedLine renders a single line with multiple formatted segments, with word wrapping
-func (tp *TextProcessor) drawFormattedLine(dc *gg.Context, segments []FormattedText, x, y, w, baseSize float64, baseColor color.Color, align string) float64 {
+// drawFormattedLine renders a single line with multiple formatted segments, with word wrapping.
+// The first wrapped line is shifted right by firstIndent.
+func (tp *TextProcessor) drawFormattedLine(dc *gg.Context, segments []FormattedText, x, y, w, firstIndent float64, family string, baseSize float64, baseColor color.Color, align, breakMode string) float64 {
 	if len(segments) == 0 {
 		return y + baseSize*1.2
 	}
 
 	// Convert segments into wrapped lines with formatting preserved
-	wrappedLines := tp.wrapFormattedSegments(dc, segments, w, baseSize, baseColor)
+	wrappedLines, _ := tp.wrapFormattedSegments(dc, segments, w, firstIndent, family, baseSize, baseColor, breakMode)
 
 	// Render each wrapped line
 	currentY := y
 
-	for _, line := range wrappedLines {
-		currentY = tp.renderWrappedFormattedLine(dc, line, x, currentY, w, baseSize, baseColor, align)
+	for i, line := range wrappedLines {
+		if i == 0 {
+			currentY = tp.renderWrappedFormattedLine(dc, line, x+firstIndent, currentY, w-firstIndent, family, baseSize, baseColor, align)
+			continue
+		}
+		currentY = tp.renderWrappedFormattedLine(dc, line, x, currentY, w, family, baseSize, baseColor, align)
 	}
 
 	return currentY
 }
 
-// wrapFormattedSegments wraps formatted text segments across multiple lines
-func (tp *TextProcessor) wrapFormattedSegments(dc *gg.Context, segments []FormattedText, maxWidth float64, baseSize float64, baseColor color.Color) [][]FormattedText {
+// wrapFormattedSegments wraps formatted text segments across multiple lines,
+// leaving firstIndent less room on the first line.
+// It also reports, per wrapped line, whether a separating space was dropped
+// where the line starts, so split paragraphs can be joined back together.
+func (tp *TextProcessor) wrapFormattedSegments(dc *gg.Context, segments []FormattedText, maxWidth, firstIndent float64, family string, baseSize float64, baseColor color.Color, breakMode string) ([][]FormattedText, []bool) {
 	var wrappedLines [][]FormattedText
+	var spaced []bool
 	var currentLine []FormattedText
-	currentLineWidth := 0.0
+	currentSpaced := false
+	currentLineWidth := firstIndent // The indent uses up part of the first line
+	spacePending := false           // Previous segment ended in whitespace
 
 	for _, segment := range segments {
 		// Set font for this segment to measure accurately
-		tp.setFont(dc, baseSize, segment.Style.Bold, segment.Style.Italic, baseColor)
+		tp.setFont(dc, family, segmentSize(segment.Style, baseSize), segment.Style.Bold, segment.Style.Italic, baseColor)
 
-		// Split segment into words
-		words := strings.Fields(segment.Content)
-		if len(words) == 0 {
-			continue
-		}
+		// Split segment into breakable tokens (words, or characters for CJK)
+		tokens := splitWrapTokens(segment.Content, breakMode)
 
-		for i, word := range words {
-			// Add space before word (except for first word in segment)
-			testWord := word
-			if i > 0 {
-				testWord = " " + word
+		for i, token := range tokens {
+			// Add space before token unless it starts the line or continues a word/CJK run
+			content := token.Text
+			if (token.SpaceBefore || (i == 0 && spacePending)) && len(currentLine) > 0 {
+				content = " " + token.Text
 			}
 
-			wordWidth, _ := dc.MeasureString(testWord)
+			tokenWidth, _ := dc.MeasureString(content)
 
-			// Check if adding this word would exceed the line width
-			if currentLineWidth+wordWidth > maxWidth && len(currentLine) > 0 {
+			// Check if adding this token would exceed the line width
+			if currentLineWidth+tokenWidth > maxWidth && len(currentLine) > 0 {
 				// Start a new line
 				wrappedLines = append(wrappedLines, currentLine)
+				spaced = append(spaced, currentSpaced)
 				currentLine = []FormattedText{}
 				currentLineWidth = 0.0
+				currentSpaced = content != token.Text
 
-				// Add the word to the new line (without leading space)
-				wordWidth, _ = dc.MeasureString(word)
-				currentLine = append(currentLine, FormattedText{
-					Content: word,
-					Style:   segment.Style,
-				})
-				currentLineWidth = wordWidth
-			} else {
-				// Add word to current line
-				if i == 0 && len(currentLine) == 0 {
-					// First word in first segment on line
-					currentLine = append(currentLine, FormattedText{
-						Content: word,
-						Style:   segment.Style,
-					})
-				} else {
-					// Add word with space prefix if needed
-					content := word
-					if i > 0 || len(currentLine) > 0 {
-						content = " " + word
-					}
-					currentLine = append(currentLine, FormattedText{
-						Content: content,
-						Style:   segment.Style,
-					})
-				}
-				currentLineWidth += wordWidth
+				// Add the token to the new line (without leading space)
+				content = token.Text
+				tokenWidth, _ = dc.MeasureString(content)
 			}
+
+			currentLine = append(currentLine, FormattedText{
+				Content: content,
+				Style:   segment.Style,
+			})
+			currentLineWidth += tokenWidth
+		}
+
+		if trimmed := strings.TrimRightFunc(segment.Content, unicode.IsSpace); trimmed != segment.Content {
+			spacePending = true
+		} else if len(tokens) > 0 {
+			spacePending = false
 		}
 	}
 
 	// Add the last line if it has content
 	if len(currentLine) > 0 {
 		wrappedLines = append(wrappedLines, currentLine)
+		spaced = append(spaced, currentSpaced)
 	}
 
-	return wrappedLines
+	return wrappedLines, spaced
 }
 
 // renderWrappedFormattedLine renders a single wrapped line with formatted segments
-func (tp *TextProcessor) renderWrappedFormattedLine(dc *gg.Context, segments []FormattedText, x, y, w, baseSize float64, baseColor color.Color, align string) float64 {
+func (tp *TextProcessor) renderWrappedFormattedLine(dc *gg.Context, segments []FormattedText, x, y, w float64, family string, baseSize float64, baseColor color.Color, align string) float64 {
 	// Check if this is an empty line (paragraph break)
 	if len(segments) == 0 {
 		return y + baseSize*1.8 // Extra spacing for paragraph breaks
 	}
 
-	// Calculate total width of the line for alignment
+	// Calculate total width of the line for alignment, and the tallest segment
 	totalWidth := 0.0
+	lineSize := baseSize
 	for _, segment := range segments {
-		tp.setFont(dc, baseSize, segment.Style.Bold, segment.Style.Italic, baseColor)
+		size := segmentSize(segment.Style, baseSize)
+		tp.setFont(dc, family, size, segment.Style.Bold, segment.Style.Italic, baseColor)
 		segmentWidth, _ := dc.MeasureString(segment.Content)
 		totalWidth += segmentWidth
+		if size > lineSize {
+			lineSize = size
+		}
 	}
 
+	// Push the baseline down so larger segments don't overlap the previous line
+	y += lineSize - baseSize
+
 	// Calculate starting X position based on alignment
 	currentX := x
 	switch align {
@@ -472,10 +672,10 @@ func (tp *TextProcessor) renderWrappedFormattedLine(dc *gg.Context, segments []F
 
 	// Render each segment with its own formatting
 	for _, segment := range segments {
-		tp.setFont(dc, baseSize, segment.Style.Bold, segment.Style.Italic, baseColor)
+		tp.setFont(dc, family, segmentSize(segment.Style, baseSize), segment.Style.Bold, segment.Style.Italic, baseColor)
 
-		// Draw the segment
-		dc.DrawStringAnchored(segment.Content, currentX, y, 0.0, 0.0)
+		// Draw the segment, raised or lowered for superscript/subscript
+		tp.drawString(dc, segment.Content, currentX, y+scriptShift(segment.Style, baseSize), 0.0)
 
 		// Move X position forward by the width of this segment
 		segmentWidth, _ := dc.MeasureString(segment.Content)
@@ -485,6 +685,61 @@ func (tp *TextProcessor) renderWrappedFormattedLine(dc *gg.Context, segments []F
 	return y + baseSize*1.5 // Increased line spacing for better readability
 }
 
+// segmentSize returns the font size for a segment, applying its size scale
+func segmentSize(style TextStyle, baseSize float64) float64 {
+	size := baseSize
+	if style.Size > 0 {
+		size *= style.Size
+	}
+	if style.Superscript || style.Subscript {
+		size *= scriptScale
+	}
+	return size
+}
+
+// scriptShift returns the vertical baseline offset for superscript/subscript
+// segments, relative to the size of the text around them
+func scriptShift(style TextStyle, baseSize float64) float64 {
+	size := baseSize
+	if style.Size > 0 {
+		size *= style.Size
+	}
+
+	switch {
+	case style.Superscript:
+		return -size * superscriptShift
+	case style.Subscript:
+		return size * subscriptShift
+	}
+	return 0
+}
+
+// leadingIndent counts a line's leading whitespace, with tabs as four spaces
+func leadingIndent(line string) int {
+	indent := 0
+	for _, ch := range line {
+		switch ch {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 4
+		default:
+			return indent
+		}
+	}
+	return indent
+}
+
+// indentWidth returns the width of indent spaces in the base font
+func (tp *TextProcessor) indentWidth(dc *gg.Context, indent int, family string, baseSize float64) float64 {
+	if indent == 0 {
+		return 0
+	}
+	tp.setFont(dc, family, baseSize, false, false, color.Black)
+	width, _ := dc.MeasureString(strings.Repeat(" ", indent))
+	return width
+}
+
 // combineSegments combines formatted segments into plain text
 func (tp *TextProcessor) combineSegments(segments []FormattedText) string {
 	var result strings.Builder
@@ -494,45 +749,59 @@ func (tp *TextProcessor) combineSegments(segments []FormattedText) string {
 	return result.String()
 }
 
-// drawSingleLine draws a single line of text with alignment
-func (tp *TextProcessor) drawSingleLine(dc *gg.Context, text string, x, y, w float64, align string) {
-	switch align {
-	case "right":
-		dc.DrawStringAnchored(text, x+w, y, 1.0, 0.0)
-	case "center":
-		dc.DrawStringAnchored(text, x+w/2, y, 0.5, 0.0)
-	default: // left
-		dc.DrawStringAnchored(text, x, y, 0.0, 0.0)
+// drawSingleLine draws text with alignment, stacking any embedded line breaks.
+// Returns the y position after the last line.
+func (tp *TextProcessor) drawSingleLine(dc *gg.Context, text string, x, y, w, lineHeight float64, align string) float64 {
+	for _, line := range strings.Split(text, "\n") {
+		switch align {
+		case "right":
+			tp.drawString(dc, line, x+w, y, 1.0)
+		case "center":
+			tp.drawString(dc, line, x+w/2, y, 0.5)
+		default: // left
+			tp.drawString(dc, line, x, y, 0.0)
+		}
+		y += lineHeight
 	}
+	return y
+}
+
+// fontState is the font and color text is currently drawn with
+type fontState struct {
+	family       string
+	size         float64
+	bold, italic bool
+	color        color.Color
 }
 
 // setFont sets up font with the specified properties
-func (tp *TextProcessor) setFont(dc *gg.Context, size float64, bold, italic bool, textColor color.Color) {
-	var fontData []byte
+func (tp *TextProcessor) setFont(dc *gg.Context, family string, size float64, bold, italic bool, textColor color.Color) {
+	// Resolve the face from the registry (falls back to the Go fonts)
+	dc.SetFontFace(tp.fonts.Face(family, size, bold, italic))
+	dc.SetColor(textColor)
+	tp.font = fontState{family: family, size: size, bold: bold, italic: italic, color: textColor}
+}
 
-	// Choose the appropriate font based on style
-	if bold && italic {
-		// For bold+italic, use bold font (closest we have)
-		fontData = gobold.TTF
-	} else if bold {
-		fontData = gobold.TTF
-	} else if italic {
-		fontData = goitalic.TTF
-	} else {
-		fontData = goregular.TTF
+// drawString draws text in the current font with its baseline at y, shifted
+// left by ax times its width (0 = left, 0.5 = centered, 1 = right aligned)
+func (tp *TextProcessor) drawString(dc *gg.Context, text string, x, y, ax float64) {
+	if tp.recorder == nil {
+		dc.DrawStringAnchored(text, x, y, ax, 0)
+		return
 	}
+	width, _ := dc.MeasureString(text)
+	tp.recorder.text(text, x-ax*width, y, tp.font)
+}
 
-	f, err := truetype.Parse(fontData)
-	if err != nil {
-		// Fallback to regular font
-		f, _ = truetype.Parse(goregular.TTF)
+// drawRule draws a gray horizontal rule from x1 to x2 at y
+func (tp *TextProcessor) drawRule(dc *gg.Context, x1, x2, y float64) {
+	ruleColor := color.RGBA{128, 128, 128, 255}
+	if tp.recorder != nil {
+		tp.recorder.line(x1, y, x2, y, ruleColor, tp.lineWidth)
+		return
 	}
-
-	face := truetype.NewFace(f, &truetype.Options{
-		Size: size,
-		DPI:  72,
-	})
-
-	dc.SetFontFace(face)
-	dc.SetColor(textColor)
+	dc.SetColor(ruleColor)
+	dc.SetLineWidth(tp.lineWidth)
+	dc.DrawLine(x1, y, x2, y)
+	dc.Stroke()
 }
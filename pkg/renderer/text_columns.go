@@ -0,0 +1,63 @@
+package renderer
+
+import (
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// splitColumns fills columns of width w and height h in order, returning
+// the lines each column holds and the lines that fit in none of them. Blank
+// lines that would start a column are dropped.
+func (tp *TextProcessor) splitColumns(dc *gg.Context, lines []FormattedLine, columns int, w, h float64, breakMode string, baseFont *templates.Font, vars map[string]string) ([][]FormattedLine, []FormattedLine) {
+	filled := make([][]FormattedLine, 0, columns)
+	rest := lines
+	for i := 0; i < columns && len(rest) > 0; i++ {
+		if i > 0 {
+			rest = trimLeadingBlankLines(rest)
+		}
+
+		var column []FormattedLine
+		column, rest = tp.splitFormattedText(dc, rest, w, h, breakMode, baseFont, vars)
+		filled = append(filled, column)
+	}
+	return filled, rest
+}
+
+// drawColumns flows formatted text from the top of the first column down
+// to its bottom edge, then on into the next, with columns of the layer's
+// column width side by side. With clip set the lines that fit in no column
+// are returned; otherwise they're drawn on down the last column.
+func (tp *TextProcessor) drawColumns(dc *gg.Context, lines []FormattedLine, layer templates.Layer, x, y, h float64, baseFont *templates.Font, vars map[string]string, clip bool) []FormattedLine {
+	w := layer.ColumnWidth()
+	filled, rest := tp.splitColumns(dc, lines, layer.Columns, w, h, layer.BreakMode, baseFont, vars)
+	if !clip && len(rest) > 0 && len(filled) > 0 {
+		last := len(filled) - 1
+		filled[last] = append(append([]FormattedLine{}, filled[last]...), trimLeadingBlankLines(rest)...)
+		rest = nil
+	}
+
+	for i, column := range filled {
+		columnX := x + float64(i)*(w+layer.Gap())
+		tp.drawFromTop(dc, column, columnX, y, w, h, layer.Align, layer.BreakMode, baseFont, vars, false)
+	}
+	return rest
+}
+
+// measureColumns returns the column height the text needs: the region's
+// height when it fits, or that plus the height of what no column can hold
+func (tp *TextProcessor) measureColumns(dc *gg.Context, lines []FormattedLine, layer templates.Layer, h float64, baseFont *templates.Font, vars map[string]string) float64 {
+	_, rest := tp.splitColumns(dc, lines, layer.Columns, layer.ColumnWidth(), h, layer.BreakMode, baseFont, vars)
+	if len(rest) == 0 {
+		return h
+	}
+	return h + tp.MeasureFormattedText(dc, trimLeadingBlankLines(rest), layer.ColumnWidth(), layer.BreakMode, baseFont, vars)
+}
+
+// trimLeadingBlankLines drops empty lines from the start of lines
+func trimLeadingBlankLines(lines []FormattedLine) []FormattedLine {
+	for len(lines) > 0 && lines[0].Type == "normal" && len(lines[0].Segments) == 0 {
+		lines = lines[1:]
+	}
+	return lines
+}
@@ -0,0 +1,50 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+	"github.com/fogleman/gg"
+)
+
+// lineText joins the text of formatted lines, one per line
+func lineText(lines []FormattedLine) string {
+	var text []string
+	for _, line := range lines {
+		var content string
+		for _, segment := range line.Segments {
+			content += segment.Content
+		}
+		text = append(text, content)
+	}
+	return strings.Join(text, "\n")
+}
+
+func TestSplitColumns(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+		rest    string
+	}{
+		{"one\ntwo\nthree", []string{"one\ntwo", "three"}, ""},
+		{"one\ntwo\nthree\nfour\nfive", []string{"one\ntwo", "three\nfour"}, "five"}, // Overflows the last column
+		{"one\ntwo\n\nthree", []string{"one\ntwo\n", "three"}, ""},                   // No blank line atop a column
+		{"one", []string{"one"}, ""},
+	}
+
+	tp := NewTextProcessor(NewFontRegistry())
+	dc := gg.NewContext(200, 100)
+	font := &templates.Font{Size: 10} // Lines are 12px, so two fit in 30px
+	for _, test := range tests {
+		filled, rest := tp.splitColumns(dc, tp.ProcessMarkdown(test.content), 2, 90, 30, "", font, nil)
+
+		var got []string
+		for _, column := range filled {
+			got = append(got, lineText(column))
+		}
+		if strings.Join(got, "|") != strings.Join(test.want, "|") || lineText(rest) != test.rest {
+			t.Errorf("%q: columns %q, rest %q, want %q and %q", test.content, got, lineText(rest), test.want, test.rest)
+		}
+	}
+}
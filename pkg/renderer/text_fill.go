@@ -0,0 +1,102 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/fogleman/gg"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// fillThroughMask paints a layer's gradient or texture fill onto dc, clipped to
+// the alpha of mask. The fill spans the drawn glyphs rather than the layer region,
+// since ascenders can extend past it. Falls back to the font's solid color if the
+// fill is unusable.
+func (r *Renderer) fillThroughMask(dc *gg.Context, mask *image.Alpha, layer templates.Layer, font *templates.Font, vars map[string]string) error {
+	bounds := maskBounds(mask)
+	if bounds.Empty() {
+		return nil // Nothing was drawn
+	}
+	region := templates.Region{X: bounds.Min.X, Y: bounds.Min.Y, Width: bounds.Dx(), Height: bounds.Dy()}
+
+	// gg's Pop keeps the mask, so clear it explicitly when done
+	dc.Push()
+	defer dc.Pop()
+	defer dc.ResetClip()
+	if err := dc.SetMask(mask); err != nil {
+		return fmt.Errorf("failed to apply text mask: %v", err)
+	}
+
+	// Texture fill takes precedence over a gradient
+	if font.FillImage != "" {
+		path := r.variableProcessor.SubstituteVariables(font.FillImage, vars)
+		img, err := r.imageProcessor.LoadImage(path)
+		if err == nil {
+			dc.DrawImage(r.imageProcessor.CreateFittedImage(img, region, "fill"), region.X, region.Y)
+			return nil
+		}
+		r.log.Debugf("Layer %s: fill image %s unavailable: %v", layer.Name, path, err)
+	}
+
+	if font.FillGradient != nil && len(font.FillGradient.Colors) > 0 {
+		dc.SetFillStyle(r.linearGradient(region, font.FillGradient, vars))
+	} else {
+		fill, err := r.utils.ParseColor(r.variableProcessor.SubstituteVariables(font.Color, vars))
+		if err != nil {
+			fill, _ = r.utils.ParseColor("#000000")
+		}
+		dc.SetColor(fill)
+	}
+
+	dc.DrawRectangle(float64(region.X), float64(region.Y), float64(region.Width), float64(region.Height))
+	dc.Fill()
+	return nil
+}
+
+// linearGradient builds a gradient spanning region at the configured angle
+func (r *Renderer) linearGradient(region templates.Region, gradient *templates.Gradient, vars map[string]string) gg.Gradient {
+	cx := float64(region.X) + float64(region.Width)/2
+	cy := float64(region.Y) + float64(region.Height)/2
+
+	// Project the region onto the gradient direction so the stops span it exactly
+	angle := gradient.Angle * math.Pi / 180
+	dx, dy := math.Cos(angle), math.Sin(angle)
+	half := (math.Abs(dx)*float64(region.Width) + math.Abs(dy)*float64(region.Height)) / 2
+
+	grad := gg.NewLinearGradient(cx-dx*half, cy-dy*half, cx+dx*half, cy+dy*half)
+	for i, stop := range gradient.Colors {
+		c, err := r.utils.ParseColor(r.variableProcessor.SubstituteVariables(stop, vars))
+		if err != nil {
+			continue
+		}
+
+		offset := 0.0
+		if len(gradient.Colors) > 1 {
+			offset = float64(i) / float64(len(gradient.Colors)-1)
+		}
+		grad.AddColorStop(offset, c)
+	}
+
+	return grad
+}
+
+// maskBounds returns the smallest rectangle containing every non-transparent pixel
+func maskBounds(mask *image.Alpha) image.Rectangle {
+	rect := mask.Bounds()
+	minX, minY, maxX, maxY := rect.Max.X, rect.Max.Y, rect.Min.X-1, rect.Min.Y-1
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if mask.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			minX, maxX = min(minX, x), max(maxX, x)
+			minY, maxY = min(minY, y), max(maxY, y)
+		}
+	}
+	if maxX < minX {
+		return image.Rectangle{}
+	}
+	return image.Rect(minX, minY, maxX+1, maxY+1)
+}
@@ -0,0 +1,83 @@
+package renderer
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+)
+
+// placedLine is one drawn line (a header line, rule or wrapped text line)
+// with its ink extent relative to the first line's baseline
+type placedLine struct {
+	index  int // Formatted line it belongs to
+	wrap   int // Wrapped line within a paragraph; 0 for headers and rules
+	top    float64
+	bottom float64
+}
+
+// layoutLines places formatted lines the way drawLines advances through them,
+// taking each line's extent from the font's ascent and descent
+func (tp *TextProcessor) layoutLines(dc *gg.Context, lines []FormattedLine, w float64, family string, baseSize float64, breakMode string) []placedLine {
+	var placed []placedLine
+	baseline := 0.0
+
+	for i, line := range lines {
+		switch line.Type {
+		case "header":
+			headerSize := baseSize * (2.0 - float64(line.Level)*0.2)
+			ascent, descent := tp.faceExtent(family, headerSize, true)
+			for range strings.Split(tp.combineSegments(line.Segments), "\n") {
+				placed = append(placed, placedLine{index: i, top: baseline - ascent, bottom: baseline + descent})
+				baseline += headerSize * 1.4
+			}
+		case "hr":
+			ruleY := baseline + baseSize*0.25
+			placed = append(placed, placedLine{index: i, top: ruleY - 0.5, bottom: ruleY + 0.5})
+			baseline += baseSize * 0.5
+		case "normal":
+			if len(line.Segments) == 0 {
+				baseline += baseSize * 1.2 * 0.5 // Empty line
+				continue
+			}
+			indent := tp.indentWidth(dc, line.Indent, family, baseSize)
+			wrappedLines, _ := tp.wrapFormattedSegments(dc, line.Segments, w-indent, line.FirstIndent, family, baseSize, color.Black, breakMode)
+			for j, wrapped := range wrappedLines {
+				lineSize := baseSize
+				for _, segment := range wrapped {
+					if size := segmentSize(segment.Style, baseSize); size > lineSize {
+						lineSize = size
+					}
+				}
+
+				// Larger segments push the baseline down, as in renderWrappedFormattedLine
+				baseline += lineSize - baseSize
+				ascent, descent := tp.faceExtent(family, lineSize, false)
+				placed = append(placed, placedLine{index: i, wrap: j, top: baseline - ascent, bottom: baseline + descent})
+				baseline += baseSize * 1.5
+			}
+		}
+	}
+
+	return placed
+}
+
+// faceExtent returns the ascent and descent of a family at a size
+func (tp *TextProcessor) faceExtent(family string, size float64, bold bool) (float64, float64) {
+	metrics := tp.fonts.Face(family, size, bold, false).Metrics()
+	return float64(metrics.Ascent) / 64, float64(metrics.Descent) / 64
+}
+
+// textExtent returns the top of the highest and bottom of the lowest placed line
+func textExtent(placed []placedLine) (float64, float64) {
+	if len(placed) == 0 {
+		return 0, 0
+	}
+	top, bottom := math.Inf(1), math.Inf(-1)
+	for _, line := range placed {
+		top = math.Min(top, line.top)
+		bottom = math.Max(bottom, line.bottom)
+	}
+	return top, bottom
+}
@@ -0,0 +1,97 @@
+package renderer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// transformPattern matches formatting transforms like {{pad:card.print_this:3}}
+var transformPattern = regexp.MustCompile(`\{\{(\w+):([^{}:]+)(?::([^{}]*))?\}\}`)
+
+// numberPattern matches plain decimal numbers with an optional sign and fraction
+var numberPattern = regexp.MustCompile(`^([+-]?)(\d+)(\.\d+)?$`)
+
+// applyTransforms resolves {{transform:variable:arg}} patterns.
+// Unknown transforms and missing variables are left untouched.
+func applyTransforms(text string, vars map[string]string) string {
+	if !strings.Contains(text, ":") {
+		return text
+	}
+
+	return transformPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := transformPattern.FindStringSubmatch(match)
+		name, key, arg := parts[1], strings.TrimSpace(parts[2]), parts[3]
+
+		// {{map:name:variable}} names the map first
+		if name == "map" {
+			return mapValue(key, arg, vars)
+		}
+
+		value, exists := vars[key]
+		if !exists {
+			return match
+		}
+
+		switch name {
+		case "pad":
+			return padNumber(value, arg)
+		case "comma":
+			return commaNumber(value)
+		default:
+			return match
+		}
+	})
+}
+
+// mapValue looks a variable's value up in a template map, ignoring case:
+// {{map:title_colors:card.rarity}} is maps.title_colors.<rarity>, or the
+// map's "default" entry when the value has none
+func mapValue(name, variable string, vars map[string]string) string {
+	prefix := "maps." + name + "."
+	value := strings.ToLower(strings.TrimSpace(vars[strings.TrimSpace(variable)]))
+	if mapped, exists := vars[prefix+value]; exists && value != "" {
+		return mapped
+	}
+	return vars[prefix+defaultVariant]
+}
+
+// padNumber zero-pads the digits of a numeric value to the given width,
+// keeping any sign in front (e.g. "-7" padded to 3 is "-007")
+func padNumber(value, widthArg string) string {
+	width, err := strconv.Atoi(strings.TrimSpace(widthArg))
+	if err != nil || width <= 0 {
+		return value
+	}
+
+	parts := numberPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if parts == nil {
+		return value // Non-numeric values pass through unchanged
+	}
+
+	sign, digits, fraction := parts[1], parts[2], parts[3]
+	if len(digits) < width {
+		digits = strings.Repeat("0", width-len(digits)) + digits
+	}
+	return sign + digits + fraction
+}
+
+// commaNumber inserts thousands separators into a numeric value
+func commaNumber(value string) string {
+	parts := numberPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if parts == nil {
+		return value // Non-numeric values pass through unchanged
+	}
+
+	sign, digits, fraction := parts[1], parts[2], parts[3]
+
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return sign + grouped.String() + fraction
+}
@@ -0,0 +1,115 @@
+package renderer
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+func TestNumberTransforms(t *testing.T) {
+	vars := map[string]string{
+		"card.print_this": "7",
+		"card.negative":   "-7",
+		"card.long":       "12345",
+		"card.price":      "1234.50",
+		"card.debt":       "-1234567",
+		"card.rarity":     "rare",
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"{{pad:card.print_this:3}}", "007"},
+		{"{{pad:card.long:3}}", "12345"}, // Wider values are kept whole
+		{"{{pad:card.print_this:1}}", "7"},
+		{"{{pad:card.negative:3}}", "-007"},
+		{"{{pad:card.price:6}}", "001234.50"},
+		{"{{pad:card.rarity:3}}", "rare"}, // Non-numeric values pass through
+		{"{{pad:card.print_this:x}}", "7"},
+		{"{{pad:card.missing:3}}", "{{pad:card.missing:3}}"},
+		{"{{comma:card.long}}", "12,345"},
+		{"{{comma:card.debt}}", "-1,234,567"},
+		{"{{comma:card.price}}", "1,234.50"},
+		{"{{comma:card.print_this}}", "7"},
+		{"{{comma:card.rarity}}", "rare"},
+	}
+
+	for _, test := range tests {
+		if got := applyTransforms(test.text, vars); got != test.want {
+			t.Errorf("applyTransforms(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestMapTransform(t *testing.T) {
+	vars := map[string]string{
+		"maps.title_colors.legendary": "#cc0000",
+		"maps.title_colors.default":   "#000000",
+		"maps.sizes.common":           "24",
+		"card.rarity":                 "Legendary",
+		"card.other":                  "common",
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"{{map:title_colors:card.rarity}}", "#cc0000"},  // Case-insensitive match
+		{"{{map:title_colors:card.other}}", "#000000"},   // Falls back to default
+		{"{{map:title_colors:card.missing}}", "#000000"}, // Unset variable uses default
+		{"{{map:sizes:card.rarity}}", ""},                // No match and no default
+		{"size {{map: sizes : card.other }}", "size 24"}, // Spaces are ignored
+	}
+
+	for _, test := range tests {
+		if got := applyTransforms(test.text, vars); got != test.want {
+			t.Errorf("applyTransforms(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+// renderTextInk renders a white card with one block of text in fontColor,
+// which may use the given maps, and returns the color of the ink
+func renderTextInk(t *testing.T, fontColor string, card *metadata.Card, maps map[string]map[string]string) color.NRGBA {
+	t.Helper()
+
+	template := &templates.Template{
+		Name:       "ink",
+		Dimensions: templates.Dimensions{Width: 100, Height: 40},
+		Background: "#ffffff",
+		Maps:       maps,
+		Layers: []templates.Layer{{
+			Name:    "text",
+			Type:    "text",
+			Content: "█████",
+			Region:  templates.Region{X: 0, Y: 0, Width: 100, Height: 40},
+			Font:    &templates.Font{Size: 30, Color: fontColor},
+		}},
+	}
+	img, err := NewRenderer().RenderImage(card, template)
+	if err != nil {
+		t.Fatalf("failed to render %s text: %v", fontColor, err)
+	}
+	return color.NRGBAModel.Convert(img.At(20, 20)).(color.NRGBA)
+}
+
+func TestMappedFontColor(t *testing.T) {
+	maps := map[string]map[string]string{
+		"title_colors": {"legendary": "#ff0000", "common": "not-a-color"},
+	}
+	inkColor := func(rarity string) color.NRGBA {
+		card := &metadata.Card{TCG: "mtg", Rarity: rarity, PrintThis: 1, PrintTotal: 1}
+		return renderTextInk(t, "{{map:title_colors:card.rarity}}", card, maps)
+	}
+
+	if got := inkColor("legendary"); got.R < 200 || got.G > 50 || got.B > 50 {
+		t.Errorf("legendary title drawn in %v, want red", got)
+	}
+	// An invalid mapped color falls back to black instead of failing
+	if got := inkColor("common"); got.R > 50 || got.G > 50 || got.B > 50 {
+		t.Errorf("common title drawn in %v, want the black fallback", got)
+	}
+}
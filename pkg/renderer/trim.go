@@ -0,0 +1,70 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// trimTolerance is the per-channel difference (0-255) still treated as the
+// trim color, so compression noise around solid backgrounds is trimmed too
+const trimTolerance = 8
+
+// TrimImage crops borders made only of fully transparent pixels, or of
+// background when it's non-nil, from an image. Images that are entirely
+// border are returned unchanged. Results are cached by path and background.
+func (ip *ImageProcessor) TrimImage(path string, img image.Image, background color.Color) image.Image {
+	key := trimKey(path, background)
+	if cached, exists := ip.trimmed[key]; exists {
+		return cached
+	}
+
+	isBorder := func(x, y int) bool {
+		r, g, b, a := img.At(x, y).RGBA()
+		if a == 0 {
+			return true
+		}
+		if background == nil {
+			return false
+		}
+		br, bg, bb, ba := background.RGBA()
+		return within(r, br) && within(g, bg) && within(b, bb) && within(a, ba)
+	}
+
+	bounds := img.Bounds()
+	trim := image.Rectangle{Min: bounds.Max, Max: bounds.Min}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if isBorder(x, y) {
+				continue
+			}
+			trim = trim.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+
+	result := img
+	if !trim.Empty() && trim != bounds {
+		out := image.NewNRGBA(image.Rect(0, 0, trim.Dx(), trim.Dy()))
+		draw.Draw(out, out.Bounds(), img, trim.Min, draw.Src)
+		result = out
+	}
+
+	ip.trimmed[key] = result
+	return result
+}
+
+// trimKey identifies a trimmed image by source path and trim color
+func trimKey(path string, background color.Color) string {
+	if background == nil {
+		return path + "|trim"
+	}
+	r, g, b, a := background.RGBA()
+	return fmt.Sprintf("%s|trim:%d,%d,%d,%d", path, r>>8, g>>8, b>>8, a>>8)
+}
+
+// within reports whether two 16-bit channel values are within trimTolerance
+func within(a, b uint32) bool {
+	diff := int(a>>8) - int(b>>8)
+	return diff >= -trimTolerance && diff <= trimTolerance
+}
@@ -3,10 +3,24 @@ package renderer
 import (
 	"fmt"
 	"image/color"
+	"math"
+	"regexp"
 	"strconv"
 	"strings"
+
+	"golang.org/x/image/colornames"
 )
 
+// membershipPattern matches a "variable in [a, b, c]" condition
+var membershipPattern = regexp.MustCompile(`^(.+?)\s+in\s+\[(.*)\]$`)
+
+// comparisonPattern matches a "variable == value" condition, or one using
+// !=, <, >, <= or >=
+var comparisonPattern = regexp.MustCompile(`^(.+?)\s*(==|!=|<=|>=|<|>)\s*(.*)$`)
+
+// defaultPattern matches a {{variable|default}} placeholder
+var defaultPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w.]*)\s*\|([^{}]*)\}\}`)
+
 // Utils provides utility functions for the renderer
 type Utils struct{}
 
@@ -15,24 +29,83 @@ func NewUtils() *Utils {
 	return &Utils{}
 }
 
-// SubstituteVariables replaces {{variable}} patterns with actual values
+// SubstituteVariables replaces {{variable}} patterns with actual values,
+// resolving transforms such as {{map:title_colors:card.rarity}} and defaults
+// such as {{card.set|Unknown}} along the way
 func (u *Utils) SubstituteVariables(template string, vars map[string]string) string {
-	result := template
-	for key, value := range vars {
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, value)
+	return substituteVariables(template, vars)
+}
+
+// substituteVariables resolves every placeholder in text in a single pass, so
+// a substituted value (such as card text) is never read as template syntax
+func substituteVariables(text string, vars map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
 	}
-	return result
+
+	return variablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		switch {
+		case transformPattern.MatchString(placeholder):
+			return applyTransforms(placeholder, vars)
+		case defaultPattern.MatchString(placeholder):
+			return applyDefaults(placeholder, vars)
+		}
+		if value, exists := vars[placeholder[2:len(placeholder)-2]]; exists {
+			return value
+		}
+		return placeholder
+	})
 }
 
-// ParseColor parses a color string (hex format)
+// applyDefaults resolves {{variable|default}} placeholders to the variable's
+// value, or to the default text as written when the variable is unset or empty
+func applyDefaults(text string, vars map[string]string) string {
+	if !strings.Contains(text, "|") {
+		return text
+	}
+
+	return defaultPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		match := defaultPattern.FindStringSubmatch(placeholder)
+		if value := vars[match[1]]; value != "" {
+			return value
+		}
+		return match[2]
+	})
+}
+
+// ParseColor parses a color string: hex ("#RGB", "#RRGGBB" or "#RRGGBBAA"),
+// "rgb(r, g, b)", "rgba(r, g, b, a)" or a CSS color name such as "white" or "navy"
 func (u *Utils) ParseColor(colorStr string) (color.Color, error) {
+	colorStr = strings.TrimSpace(colorStr)
+	name := strings.ToLower(colorStr)
+	if named, exists := colornames.Map[name]; exists {
+		return named, nil
+	}
+	if strings.HasPrefix(name, "rgb(") || strings.HasPrefix(name, "rgba(") {
+		return parseColorFunction(name, colorStr)
+	}
+
 	if !strings.HasPrefix(colorStr, "#") {
 		return color.Black, fmt.Errorf("invalid color format: %s", colorStr)
 	}
 
 	colorStr = strings.TrimPrefix(colorStr, "#")
 
+	// Shorthand "#abc" doubles each digit: "#aabbcc"
+	if len(colorStr) == 3 {
+		if _, err := strconv.ParseUint(colorStr, 16, 16); err != nil {
+			return color.Black, fmt.Errorf("invalid color format: #%s", colorStr)
+		}
+		colorStr = string([]byte{colorStr[0], colorStr[0], colorStr[1], colorStr[1], colorStr[2], colorStr[2]})
+	}
+
+	// Every digit must be hex, or "#zzzzzz80" would draw translucent black
+	if len(colorStr) == 6 || len(colorStr) == 8 {
+		if _, err := strconv.ParseUint(colorStr, 16, 32); err != nil {
+			return color.Black, fmt.Errorf("invalid color format: #%s", colorStr)
+		}
+	}
+
 	if len(colorStr) == 6 {
 		// RGB format
 		r, _ := strconv.ParseUint(colorStr[0:2], 16, 8)
@@ -41,27 +114,204 @@ func (u *Utils) ParseColor(colorStr string) (color.Color, error) {
 		return color.RGBA{uint8(r), uint8(g), uint8(b), 255}, nil
 	}
 
+	if len(colorStr) == 8 {
+		// RGBA format
+		r, _ := strconv.ParseUint(colorStr[0:2], 16, 8)
+		g, _ := strconv.ParseUint(colorStr[2:4], 16, 8)
+		b, _ := strconv.ParseUint(colorStr[4:6], 16, 8)
+		a, _ := strconv.ParseUint(colorStr[6:8], 16, 8)
+		return color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)}, nil
+	}
+
 	return color.Black, fmt.Errorf("unsupported color format: %s", colorStr)
 }
 
-// EvaluateCondition evaluates a simple condition
-func (u *Utils) EvaluateCondition(condition string, vars map[string]string) bool {
-	// Simple condition evaluation - check if variables exist and are non-empty
-	condition = strings.TrimSpace(condition)
+// parseColorFunction parses "rgb(r, g, b)" or "rgba(r, g, b, a)". Channels
+// are clamped to 0-255 and the alpha fraction to 0-1.
+func parseColorFunction(function, colorStr string) (color.Color, error) {
+	name, args, _ := strings.Cut(function, "(")
+	args, closed := strings.CutSuffix(strings.TrimSpace(args), ")")
+	parts := strings.Split(args, ",")
+	if !closed || (name == "rgb" && len(parts) != 3) || (name == "rgba" && len(parts) != 4) {
+		return color.Black, fmt.Errorf("invalid color format: %s", colorStr)
+	}
 
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return color.Black, fmt.Errorf("invalid color format: %s", colorStr)
+		}
+		values[i] = value
+	}
+
+	channel := func(value float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(255, value))))
+	}
+	if name == "rgb" {
+		return color.RGBA{channel(values[0]), channel(values[1]), channel(values[2]), 255}, nil
+	}
+	alpha := channel(255 * math.Max(0, math.Min(1, values[3])))
+	return color.NRGBA{channel(values[0]), channel(values[1]), channel(values[2]), alpha}, nil
+}
+
+// EvaluateCondition evaluates a condition: operands joined with && and ||
+// (&& binds tighter), grouped with parentheses and negated with !. A bare
+// variable is true when it's set and non-empty. An empty condition is true.
+func (u *Utils) EvaluateCondition(condition string, vars map[string]string) bool {
 	// Remove {{ }} brackets
 	condition = strings.ReplaceAll(condition, "{{", "")
 	condition = strings.ReplaceAll(condition, "}}", "")
 
-	// Split on && (simple AND logic)
-	parts := strings.Split(condition, "&&")
+	if strings.TrimSpace(condition) == "" {
+		return true
+	}
+	return evaluateOr(condition, vars)
+}
 
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if value, exists := vars[part]; !exists || value == "" || value == "null" {
-			return false
+// evaluateOr reports whether any of the ||-separated alternatives is true
+func evaluateOr(condition string, vars map[string]string) bool {
+	for _, alternative := range splitTopLevel(condition, "||") {
+		if evaluateAnd(alternative, vars) {
+			return true
 		}
 	}
+	return false
+}
 
+// evaluateAnd reports whether all of the &&-separated operands are true
+func evaluateAnd(condition string, vars map[string]string) bool {
+	for _, operand := range splitTopLevel(condition, "&&") {
+		if !evaluateOperand(strings.TrimSpace(operand), vars) {
+			return false
+		}
+	}
 	return true
 }
+
+// evaluateOperand evaluates a group, test or bare variable, any of which a
+// leading ! negates
+func evaluateOperand(part string, vars map[string]string) bool {
+	if negated, found := strings.CutPrefix(part, "!"); found {
+		return !evaluateOperand(strings.TrimSpace(negated), vars)
+	}
+
+	// Group: "(mtg.legendary || mtg.mythic)"
+	if strings.HasPrefix(part, "(") && closingParen(part) == len(part)-1 {
+		return evaluateOr(part[1:len(part)-1], vars)
+	}
+
+	// Membership test: "card.rarity in [rare, mythic]"
+	if match := membershipPattern.FindStringSubmatch(part); match != nil {
+		return isMember(vars[strings.TrimSpace(match[1])], match[2])
+	}
+
+	// Comparison: "card.rarity == rare", "card.set != PROMO", "mtg.cmc >= 7"
+	if match := comparisonPattern.FindStringSubmatch(part); match != nil {
+		switch match[2] {
+		case "==", "!=":
+			return equalValues(vars[strings.TrimSpace(match[1])], match[3]) == (match[2] == "==")
+		default:
+			return compareNumbers(operandValue(match[1], vars), match[2], operandValue(match[3], vars))
+		}
+	}
+
+	value, exists := vars[part]
+	return exists && value != "" && value != "null"
+}
+
+// splitTopLevel splits a condition on an operator, except inside parentheses
+// or membership brackets
+func splitTopLevel(condition, operator string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(condition); i++ {
+		switch condition[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		}
+		if depth == 0 && strings.HasPrefix(condition[i:], operator) {
+			parts = append(parts, condition[start:i])
+			start = i + len(operator)
+			i += len(operator) - 1
+		}
+	}
+	return append(parts, condition[start:])
+}
+
+// closingParen returns the index of the parenthesis closing the one that
+// opens s, or -1 if it isn't closed
+func closingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// operandValue returns the value of a variable, or the operand itself when
+// no variable has that name
+func operandValue(operand string, vars map[string]string) string {
+	operand = strings.TrimSpace(operand)
+	if value, exists := vars[operand]; exists {
+		return value
+	}
+	return operand
+}
+
+// compareNumbers compares two values numerically with <, >, <= or >=. It is
+// false when either value isn't a number.
+func compareNumbers(left, operator, right string) bool {
+	a, err := strconv.ParseFloat(strings.TrimSpace(left), 64)
+	if err != nil {
+		return false
+	}
+	b, err := strconv.ParseFloat(strings.TrimSpace(right), 64)
+	if err != nil {
+		return false
+	}
+
+	switch operator {
+	case "<":
+		return a < b
+	case ">":
+		return a > b
+	case "<=":
+		return a <= b
+	default:
+		return a >= b
+	}
+}
+
+// equalValues compares a variable's value with a condition's literal, which
+// may be quoted. Surrounding whitespace is ignored; case is not.
+func equalValues(value, literal string) bool {
+	return strings.TrimSpace(value) == strings.Trim(strings.TrimSpace(literal), `"'`)
+}
+
+// isMember reports whether value appears in a comma-separated list. Items may
+// be quoted and are compared case-insensitively.
+func isMember(value, list string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+
+	for _, item := range strings.Split(list, ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"'`)
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
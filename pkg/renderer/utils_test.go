@@ -0,0 +1,230 @@
+package renderer
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+)
+
+func TestParseHexColor(t *testing.T) {
+	utils := NewUtils()
+	for _, bad := range []string{"#zzzzzz80", "#ff0000zz", "#gg0000"} {
+		if _, err := utils.ParseColor(bad); err == nil || !strings.Contains(err.Error(), "invalid color format") {
+			t.Errorf("ParseColor(%q) error = %v, want invalid color format", bad, err)
+		}
+	}
+}
+
+func TestMembershipCondition(t *testing.T) {
+	vars := map[string]string{"card.rarity": "Rare", "card.set": "ALP"}
+
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{"card.rarity in [rare, mythic]", true},
+		{"card.set in [BET, UNL]", false},       // Absent
+		{"card.set in [\"BET\", 'ALP']", true},  // Quoted items
+		{"card.rarity in [MYTHIC, rARE]", true}, // Case-insensitive
+		{"card.missing in [ALP, '']", false},    // Unset is never a member
+		{"card.set in [ALP] && card.rarity in [common]", false},
+	}
+
+	utils := NewUtils()
+	for _, test := range tests {
+		if got := utils.EvaluateCondition(test.condition, vars); got != test.want {
+			t.Errorf("EvaluateCondition(%q) = %v, want %v", test.condition, got, test.want)
+		}
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	vars := map[string]string{
+		"card.rarity":   "Rare",
+		"card.set":      "ALP",
+		"card.title":    "Shock",
+		"card.flavor":   "",
+		"mtg.mythic":    "true",
+		"card.type":     "Legendary Creature",
+		"mtg.power":     "3",
+		"mtg.cmc":       "7",
+		"mtg.toughness": "2.5",
+	}
+
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{"{{card.title}}", true},
+		{"{{card.flavor}}", false},
+		{"{{card.rarity}} in [rare, mythic]", true},
+		{"card.rarity == Rare", true},
+		{"card.rarity == rare", false}, // Case-sensitive
+		{"{{card.rarity}} == 'Rare'", true},
+		{"card.rarity==mythic", false},
+		{"card.rarity != Rare", false},
+		{"card.rarity != rare", true},
+		{"card.set != PROMO", true},
+		{"card.missing == ''", true}, // Unset compares as empty
+		{"card.rarity == Rare && card.set == BET", false},
+		{"card.rarity == Rare && {{card.title}}", true},
+		{"", true}, // Like a layer without a condition
+		{"{{card.flavor}} || {{card.title}}", true},
+		{"{{card.flavor}} || {{mtg.legendary}}", false},
+		{"{{card.rarity}} && {{mtg.legendary}} || {{mtg.mythic}}", true}, // && binds tighter
+		{"{{mtg.mythic}} || {{card.flavor}} && {{mtg.legendary}}", true},
+		{"{{card.rarity}} && ({{mtg.legendary}} || {{card.flavor}})", false},
+		{"({{mtg.legendary}} || {{mtg.mythic}}) && card.set in [ALP, BET]", true},
+		{"card.set in [BET||ALP] || {{card.flavor}}", false}, // Brackets aren't split
+		{"{{card.title}} && ", false},
+		{"{{card.type}} == Legendary Creature && {{mtg.power}}", true}, // Literals may contain spaces
+		{"{{card.type}}==  Legendary Creature  ", true},
+		{"{{card.type}} == legendary creature", false},
+		{"{{card.type}} == Planeswalker", false},
+		{"{{card.type}} != Planeswalker && {{mtg.loyalty}}", false},
+		{"{{mtg.cmc}} >= 7", true}, // Equal
+		{"{{mtg.cmc}} > 7", false},
+		{"{{mtg.cmc}} <= 7", true},
+		{"{{mtg.cmc}} < 10", true},
+		{"{{mtg.cmc}}>6.5", true},
+		{"{{mtg.power}} > {{mtg.toughness}}", true}, // Both sides may be variables
+		{"{{card.rarity}} > 2", false},              // Not numeric
+		{"{{mtg.missing}} < 2", false},
+		{"{{mtg.cmc}} >= 7 || {{card.flavor}} && {{mtg.cmc}} < 2", true},
+		{"!{{card.flavor}}", true},
+		{"!{{card.title}}", false},
+		{"!{{card.missing}}", true},
+		{"{{card.title}} && !{{card.footer}}", true},
+		{"{{card.title}} && ! {{card.title}}", false},
+		{"!({{card.flavor}} || {{mtg.mythic}})", false},
+		{"!{{mtg.cmc}} > 7", true}, // Negates the whole comparison
+		{"!!{{card.title}}", true},
+		{"!(card.set in [BET, UNL]) && {{card.title}}", true},
+		{"!({{card.rarity}} in [rare, mythic]) || {{card.flavor}}", false},
+		{"!card.set in [alp] && {{card.title}} || {{mtg.mythic}}", true}, // Negates only the membership
+	}
+
+	utils := NewUtils()
+	for _, test := range tests {
+		if got := utils.EvaluateCondition(test.condition, vars); got != test.want {
+			t.Errorf("EvaluateCondition(%q) = %v, want %v", test.condition, got, test.want)
+		}
+	}
+}
+
+func TestParseNamedColor(t *testing.T) {
+	tests := []struct {
+		name string
+		want color.RGBA
+	}{
+		{"white", color.RGBA{255, 255, 255, 255}},
+		{"Red", color.RGBA{255, 0, 0, 255}},
+		{"  navy ", color.RGBA{0, 0, 128, 255}},
+		{"GRAY", color.RGBA{128, 128, 128, 255}},
+		{"gold", color.RGBA{255, 215, 0, 255}},
+	}
+
+	utils := NewUtils()
+	for _, test := range tests {
+		got, err := utils.ParseColor(test.name)
+		if err != nil {
+			t.Errorf("ParseColor(%q) failed: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// Hex codes parse as before, trimmed like names, and unknown names still fail
+	for _, hex := range []string{"#102030", " #102030 "} {
+		if got, err := utils.ParseColor(hex); err != nil || got != (color.RGBA{0x10, 0x20, 0x30, 255}) {
+			t.Errorf("ParseColor(%q) = %v, %v", hex, got, err)
+		}
+	}
+	if _, err := utils.ParseColor("blurple"); err == nil {
+		t.Errorf("ParseColor(blurple) succeeded, want an error")
+	}
+}
+
+func TestParseShorthandHex(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want color.RGBA
+	}{
+		{"#fff", color.RGBA{0xff, 0xff, 0xff, 255}},
+		{"#000", color.RGBA{0, 0, 0, 255}},
+		{"#AbC", color.RGBA{0xaa, 0xbb, 0xcc, 255}},
+		{"#0a8", color.RGBA{0x00, 0xaa, 0x88, 255}},
+	}
+
+	utils := NewUtils()
+	for _, test := range tests {
+		got, err := utils.ParseColor(test.hex)
+		if err != nil {
+			t.Errorf("ParseColor(%q) failed: %v", test.hex, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", test.hex, got, test.want)
+		}
+	}
+
+	for _, bad := range []string{"#xyz", "fff", "#ff"} {
+		if _, err := utils.ParseColor(bad); err == nil {
+			t.Errorf("ParseColor(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestParseColorFunction(t *testing.T) {
+	tests := []struct {
+		function string
+		want     color.Color
+	}{
+		{"rgb(34, 40, 49)", color.RGBA{34, 40, 49, 255}},
+		{"rgb(34,40,49)", color.RGBA{34, 40, 49, 255}},
+		{" RGB( 300 , -5, 12.6 ) ", color.RGBA{255, 0, 13, 255}}, // Clamped and rounded
+		{"rgba(0,0,0,0.5)", color.NRGBA{0, 0, 0, 128}},
+		{"rgba(255, 0, 0, 2)", color.NRGBA{255, 0, 0, 255}},
+	}
+
+	utils := NewUtils()
+	for _, test := range tests {
+		got, err := utils.ParseColor(test.function)
+		if err != nil {
+			t.Errorf("ParseColor(%q) failed: %v", test.function, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", test.function, got, test.want)
+		}
+	}
+
+	for _, bad := range []string{"rgb(1, 2)", "rgba(1, 2, 3)", "rgb(1, 2, 3, 4)", "rgb(a, b, c)", "rgb(1, 2, 3"} {
+		if _, err := utils.ParseColor(bad); err == nil {
+			t.Errorf("ParseColor(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestAlphaHexColor(t *testing.T) {
+	utils := NewUtils()
+
+	// Alpha is kept unpremultiplied so the color channels read as written
+	got, err := utils.ParseColor("#ff000080")
+	if err != nil || got != (color.NRGBA{255, 0, 0, 0x80}) {
+		t.Errorf("ParseColor(#ff000080) = %v, %v, want 50%% red", got, err)
+	}
+	if got, _ := utils.ParseColor("#ff0000"); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("ParseColor(#ff0000) = %v, want opaque red", got)
+	}
+
+	// A 50% black caption darkens a white card to mid gray
+	card := &metadata.Card{TCG: "mtg", PrintThis: 1, PrintTotal: 1}
+	if ink := renderTextInk(t, "#00000080", card, nil); ink.R < 120 || ink.R > 135 || ink.A != 255 {
+		t.Errorf("caption drawn as %v, want gray from 50%% black over white", ink)
+	}
+}
@@ -3,6 +3,7 @@ package renderer
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -10,30 +11,137 @@ import (
 	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
 )
 
+// Default collector line: "037/250 • R • SET • EN"
+const (
+	defaultCollectorFormat    = "{{pad:card.print_this:3}}/{{pad:card.print_total:3}} • {{card.rarity_code}} • {{card.set}} • {{card.lang}}"
+	defaultCollectorSeparator = " • "
+)
+
+// variablePattern matches {{variable}} references
+var variablePattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
 // VariableProcessor handles template variable building and substitution
 type VariableProcessor struct {
 	textProcessor *TextProcessor
+	overrides     map[string]string // Variables that win over every card's values
 }
 
 // NewVariableProcessor creates a new variable processor
 func NewVariableProcessor() *VariableProcessor {
 	return &VariableProcessor{
-		textProcessor: NewTextProcessor(),
+		textProcessor: NewTextProcessor(NewFontRegistry()),
 	}
 }
 
+// SetOverrides sets variables that replace the card-derived values of every
+// card, before computed fields such as card.collector are built from them
+func (vp *VariableProcessor) SetOverrides(overrides map[string]string) {
+	vp.overrides = overrides
+}
+
 // BuildTemplateVariables creates a map of all template variables for this card
 func (vp *VariableProcessor) BuildTemplateVariables(card *metadata.Card, template *templates.Template) map[string]string {
+	vars := vp.cardVariables(card, template.Footer)
+
+	// Expose the related card's fields as related.* ("related.title", "related.power")
+	if card.RelatedCard != nil {
+		relatedVars := vp.cardVariables(card.RelatedCard, template.Footer)
+		for key, value := range relatedVars {
+			vars["related."+key] = value
+		}
+		// Short aliases drop the namespace; card.* wins over TCG fields on collisions
+		for key, value := range relatedVars {
+			_, field, found := strings.Cut(key, ".")
+			if !found {
+				continue
+			}
+			alias := "related." + field
+			if _, exists := vars[alias]; !exists || strings.HasPrefix(key, "card.") {
+				vars[alias] = value
+			}
+		}
+
+		// The card's own text may quote the related card, e.g. a token's
+		// {{related.power}} in card.body; the related text is used as written
+		for key, value := range vars {
+			if !strings.HasPrefix(key, "related.") && strings.Contains(value, "{{related.") {
+				vars[key] = resolveRelated(value, vars)
+			}
+		}
+	}
+
+	// Add style tokens
+	for key, value := range template.StyleTokens {
+		vars["style_tokens."+key] = value
+	}
+
+	// Add lookup tables for the map transform
+	for name, entries := range template.Maps {
+		for key, value := range entries {
+			vars["maps."+name+"."+strings.ToLower(key)] = value
+		}
+	}
+
+	// Add template optional fields (includes font sizes and other defaults)
+	// where the card leaves them unset
+	for key, value := range template.Optional {
+		if vars[key] != "" {
+			continue
+		}
+		if str, ok := value.(string); ok {
+			vars[key] = str
+		} else if num, ok := value.(int); ok {
+			vars[key] = strconv.Itoa(num)
+		} else if fl, ok := value.(float64); ok {
+			vars[key] = strconv.FormatFloat(fl, 'f', -1, 64)
+		} else if value != nil {
+			vars[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	// Overrides win over the card, its frontmatter and the template defaults
+	for key, value := range vp.overrides {
+		vars[key] = value
+	}
+
+	// Add template directory
+	vars["template_dir"] = template.TemplateDir
+	vars["icon_dir"] = filepath.Join(template.TemplateDir, "icons")
+
+	// Computed fields
+	vars["card.rarity_code"] = rarityCode(vars["card.rarity"])
+	vars["rarity_color"] = rarityColor(vars)
+	vars["card.collector"] = vp.buildCollectorLine(template.Collector, vars)
+
+	// An override of a computed field itself still wins
+	for _, key := range []string{"card.rarity_code", "rarity_color", "card.collector"} {
+		if value, exists := vp.overrides[key]; exists {
+			vars[key] = value
+		}
+	}
+
+	return vars
+}
+
+// cardVariables builds the variables that come from the card itself, using
+// the template's footer rule to find card.footer
+func (vp *VariableProcessor) cardVariables(card *metadata.Card, footerRule templates.FooterRule) map[string]string {
 	vars := make(map[string]string)
 
-	// Use parsed rules text for body, fall back to full body if needed
-	body := card.RulesText
-	if body == "" {
-		body = card.Body
+	// Split the footer off the raw markdown, where its header is still
+	// present, then take the rules text from what comes before it
+	bodyContent := card.RulesText
+	source := card.Body
+	rulesSource, footer, found := vp.textProcessor.SeparateFooter(card.Body, footerRule)
+	if found {
+		bodyContent, _ = metadata.SplitRulesText(rulesSource)
+		source = rulesSource
 	}
 
-	// Separate footer from body (in case it wasn't parsed separately)
-	bodyContent, footer := vp.textProcessor.SeparateFooter(body)
+	// Fall back to the full body if no rules text was parsed
+	if bodyContent == "" {
+		bodyContent = source
+	}
 
 	// Use parsed flavor text for footer if available
 	if card.FlavorText != "" && footer == "" {
@@ -46,46 +154,25 @@ func (vp *VariableProcessor) BuildTemplateVariables(card *metadata.Card, templat
 	vars["card.rarity"] = card.Rarity
 	vars["card.set"] = card.Set
 	vars["card.artist"] = card.Artist
+	vars["card.lang"] = card.Language
 	vars["card.body"] = bodyContent
 	vars["card.footer"] = footer
 	vars["card.rules_text"] = card.RulesText
 	vars["card.flavor_text"] = card.FlavorText
 	vars["card.mana_cost"] = card.ManaCost
-	vars["card.print_this"] = strconv.Itoa(card.PrintThis)
-	vars["card.print_total"] = strconv.Itoa(card.PrintTotal)
-
-	// Add artwork from metadata if present
-	// Check for card.artwork in the nested card map
-	if cardMap, exists := card.Metadata["card"]; exists {
-		if cardMapTyped, ok := cardMap.(map[string]interface{}); ok {
-			if artwork, exists := cardMapTyped["artwork"]; exists {
-				if artworkStr, ok := artwork.(string); ok {
-					// Simple string format: card.artwork: "url"
-					vars["card.artwork"] = artworkStr
-				} else if artworkMap, ok := artwork.(map[string]interface{}); ok {
-					// Nested format: card.artwork: { url: "...", fit: "..." }
-					if url, exists := artworkMap["url"]; exists {
-						if urlStr, ok := url.(string); ok {
-							vars["card.artwork"] = urlStr // Store the URL as card.artwork
-						}
-					}
-					if fit, exists := artworkMap["fit"]; exists {
-						if fitStr, ok := fit.(string); ok {
-							vars["card.artwork.fit"] = fitStr
-						}
-					}
-				}
-			}
-		}
-	}
 
 	// Add all metadata fields
 	for key, value := range card.Metadata {
+		// Artwork is handled separately below, and a frontmatter body was
+		// already parsed into card.body
+		if key == "card.artwork" || key == "card.body" {
+			continue
+		}
+
 		// Handle nested maps (like card.artwork being in card map)
 		if nestedMap, ok := value.(map[string]interface{}); ok {
 			for nestedKey, nestedValue := range nestedMap {
-				// Skip artwork as it's handled specially above
-				if key == "card" && nestedKey == "artwork" {
+				if key == "card" && (nestedKey == "artwork" || nestedKey == "body") {
 					continue
 				}
 
@@ -111,42 +198,136 @@ func (vp *VariableProcessor) BuildTemplateVariables(card *metadata.Card, templat
 		}
 	}
 
-	// Add style tokens
-	for key, value := range template.StyleTokens {
-		vars["style_tokens."+key] = value
+	// The parsed print numbers win over the frontmatter's, which may be 0
+	vars["card.print_this"] = strconv.Itoa(card.PrintThis)
+	vars["card.print_total"] = strconv.Itoa(card.PrintTotal)
+
+	// Artwork may be given flat (card.artwork) or in the nested card map;
+	// the nested form wins like other card fields
+	artwork := card.Metadata["card.artwork"]
+	if cardMap, ok := card.Metadata["card"].(map[string]interface{}); ok && cardMap["artwork"] != nil {
+		artwork = cardMap["artwork"]
 	}
+	artworkVariables(artwork, vars)
 
-	// Add template optional fields (includes font sizes and other defaults)
-	for key, value := range template.Optional {
-		if str, ok := value.(string); ok {
-			vars[key] = str
-		} else if num, ok := value.(int); ok {
-			vars[key] = strconv.Itoa(num)
-		} else if fl, ok := value.(float64); ok {
-			vars[key] = strconv.FormatFloat(fl, 'f', -1, 64)
-		} else if value != nil {
-			vars[key] = fmt.Sprintf("%v", value)
+	// Split the mana cost into symbols so templates can place one pip per symbol
+	cost := vars["card.mana_cost"]
+	if cost == "" {
+		cost = vars["mtg.mana_cost"]
+	}
+	symbols := metadata.ParseManaCost(cost)
+	vars["mana_symbols"] = strings.Join(symbols, " ")
+	vars["mana_symbol_count"] = strconv.Itoa(len(symbols))
+	for i, symbol := range symbols {
+		position := strconv.Itoa(i + 1)
+		vars["mana_symbol."+position] = symbol
+		vars["mana_icon."+position] = manaIconName(symbol)
+	}
+
+	return vars
+}
+
+// artworkVariables sets card.artwork and card.artwork.fit from an artwork
+// value: a plain URL or path, or a map with url and fit
+func artworkVariables(artwork interface{}, vars map[string]string) {
+	switch artwork := artwork.(type) {
+	case string:
+		// Simple string format: card.artwork: "url"
+		vars["card.artwork"] = artwork
+	case map[string]interface{}:
+		// Map format: card.artwork: { url: "...", fit: "..." }
+		if url, ok := artwork["url"].(string); ok {
+			vars["card.artwork"] = url
+		}
+		if fit, ok := artwork["fit"].(string); ok {
+			vars["card.artwork.fit"] = fit
 		}
 	}
+}
 
-	// Add template directory
-	vars["template_dir"] = template.TemplateDir
-	vars["icon_dir"] = filepath.Join(template.TemplateDir, "icons")
+// buildCollectorLine assembles card.collector (e.g. "037/250 • R • SET • EN").
+// Components referencing an empty or missing variable are omitted.
+func (vp *VariableProcessor) buildCollectorLine(collector templates.CollectorLine, vars map[string]string) string {
+	format := collector.Format
+	if format == "" {
+		format = defaultCollectorFormat
+	}
+	separator := collector.Separator
+	if separator == "" {
+		separator = defaultCollectorSeparator
+	}
 
-	return vars
+	var components []string
+	for _, component := range strings.Split(format, separator) {
+		if !vp.referencesOnlySetVariables(component, vars) {
+			continue
+		}
+		if value := strings.TrimSpace(vp.SubstituteVariables(component, vars)); value != "" {
+			components = append(components, value)
+		}
+	}
+
+	return strings.Join(components, separator)
 }
 
-// SubstituteVariables replaces {{variable}} patterns with actual values
-func (vp *VariableProcessor) SubstituteVariables(template string, vars map[string]string) string {
-	result := template
+// referencesOnlySetVariables reports whether every {{variable}} in text has a non-empty value
+func (vp *VariableProcessor) referencesOnlySetVariables(text string, vars map[string]string) bool {
+	for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+		key := match[1]
+		// Transforms reference their variable as the second component (pad:card.print_this:3)
+		if parts := strings.Split(key, ":"); len(parts) > 1 {
+			key = parts[1]
+		}
+		if value := vars[strings.TrimSpace(key)]; value == "" || value == "null" {
+			return false
+		}
+	}
+	return true
+}
 
-	// Simple variable substitution for now
-	for key, value := range vars {
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, value)
+// rarityCode abbreviates a rarity to its uppercase initial ("rare" -> "R")
+func rarityCode(rarity string) string {
+	rarity = strings.TrimSpace(rarity)
+	if rarity == "" {
+		return ""
 	}
+	return strings.ToUpper(rarity[:1])
+}
 
-	return result
+// manaIconName turns a mana symbol into an icon file name: "W/U" -> "wu"
+func manaIconName(symbol string) string {
+	return strings.ToLower(strings.ReplaceAll(symbol, "/", ""))
+}
+
+// rarityColor resolves {{rarity_color}}: a style_tokens.rarity_color override
+// wins, otherwise the per-rarity token for card.rarity is used
+func rarityColor(vars map[string]string) string {
+	if color := vars["style_tokens.rarity_color"]; color != "" {
+		return color
+	}
+	rarity := strings.ToLower(strings.TrimSpace(vars["card.rarity"]))
+	if color := vars["style_tokens.rarity_color."+rarity]; color != "" {
+		return color
+	}
+	return vars["style_tokens.rarity_color.common"] // Unknown rarities look common
+}
+
+// resolveRelated replaces the {{related.*}} references in a card's text with
+// the related card's values, leaving every other placeholder as written
+func resolveRelated(text string, vars map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		key := placeholder[2 : len(placeholder)-2]
+		if value, exists := vars[key]; exists && strings.HasPrefix(key, "related.") {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// SubstituteVariables replaces {{variable}} patterns with actual values, the
+// same way as Utils.SubstituteVariables
+func (vp *VariableProcessor) SubstituteVariables(template string, vars map[string]string) string {
+	return substituteVariables(template, vars)
 }
 
 // ProcessIconReplacements handles icon replacement in text
@@ -0,0 +1,138 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+func TestPrintNumberVariables(t *testing.T) {
+	// The parser defaulted print_this: 0 to 1; the raw frontmatter still says 0
+	card := &metadata.Card{
+		TCG:        "mtg",
+		Set:        "SET",
+		Rarity:     "rare",
+		Language:   "EN",
+		PrintThis:  1,
+		PrintTotal: 250,
+		Metadata: map[string]interface{}{
+			"card.print_this":  0,
+			"card.print_total": 250,
+		},
+	}
+
+	vars := NewVariableProcessor().BuildTemplateVariables(card, &templates.Template{})
+	if vars["card.print_this"] != "1" || vars["card.print_total"] != "250" {
+		t.Errorf("got print numbers %s/%s, want 1/250", vars["card.print_this"], vars["card.print_total"])
+	}
+	if want := "001/250 • R • SET • EN"; vars["card.collector"] != want {
+		t.Errorf("got collector %q, want %q", vars["card.collector"], want)
+	}
+}
+
+func TestVariableOverrides(t *testing.T) {
+	card := &metadata.Card{
+		TCG:        "mtg",
+		Set:        "SET",
+		PrintThis:  1,
+		PrintTotal: 1,
+		Metadata:   map[string]interface{}{"card.set": "SET"},
+	}
+	template := &templates.Template{
+		Optional: map[string]interface{}{"card.artist": "Unknown Artist"},
+	}
+
+	vp := NewVariableProcessor()
+	vp.SetOverrides(map[string]string{"card.set": "PROMO", "card.artist": "Guest"})
+	vars := vp.BuildTemplateVariables(card, template)
+
+	if vars["card.set"] != "PROMO" || vars["card.artist"] != "Guest" {
+		t.Errorf("got set %q and artist %q, want the overrides", vars["card.set"], vars["card.artist"])
+	}
+	if want := "001/001 • PROMO"; vars["card.collector"] != want {
+		t.Errorf("got collector %q, want %q", vars["card.collector"], want)
+	}
+}
+
+func TestSubstituteDefaults(t *testing.T) {
+	vars := map[string]string{
+		"card.set":    "ALP",
+		"card.flavor": "",
+		"card.body":   "Set: {{card.set|none}}, art by {{card.artist|Unknown}}",
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"{{card.set|Unknown}}", "ALP"},
+		{"{{card.flavor|No flavor}}", "No flavor"}, // Empty uses the default
+		{"{{card.artist|Unknown}}", "Unknown"},     // So does unset
+		{"frames/{{ mtg.color |colorless}}_frame.png", "frames/colorless_frame.png"},
+		{"{{card.artist|}}", ""},
+		{"{{card.artist|n/a | tbd}}", "n/a | tbd"}, // Defaults are used as written
+	}
+
+	vp := NewVariableProcessor()
+	utils := NewUtils()
+	for _, test := range tests {
+		if got := vp.SubstituteVariables(test.text, vars); got != test.want {
+			t.Errorf("VariableProcessor.SubstituteVariables(%q) = %q, want %q", test.text, got, test.want)
+		}
+		if got := utils.SubstituteVariables(test.text, vars); got != test.want {
+			t.Errorf("Utils.SubstituteVariables(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	// Placeholders inside a substituted value are card text, kept as written
+	vars["card.flavor"] = "Say {{card.set}}"
+	for text, want := range map[string]string{
+		"{{card.body}}":        "Set: {{card.set|none}}, art by {{card.artist|Unknown}}",
+		"{{card.flavor|none}}": "Say {{card.set}}",
+	} {
+		if got := vp.SubstituteVariables(text, vars); got != want {
+			t.Errorf("VariableProcessor.SubstituteVariables(%q) = %q, want %q", text, got, want)
+		}
+		if got := utils.SubstituteVariables(text, vars); got != want {
+			t.Errorf("Utils.SubstituteVariables(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestOptionalFieldsDefaults(t *testing.T) {
+	card := &metadata.Card{TCG: "mtg", Artist: "A. Painter", PrintThis: 3, PrintTotal: 10}
+	template := &templates.Template{
+		Optional: map[string]interface{}{
+			"card.artist":     "Unknown Artist",
+			"card.print_this": 1,
+			"card.set":        "PRM",
+			"title_font_size": 24,
+		},
+	}
+
+	vars := NewVariableProcessor().BuildTemplateVariables(card, template)
+
+	// The card's own values win over the template's defaults
+	if vars["card.artist"] != "A. Painter" || vars["card.print_this"] != "3" {
+		t.Errorf("got artist %q and print_this %q, want the card's values", vars["card.artist"], vars["card.print_this"])
+	}
+	// Defaults fill the variables the card leaves unset
+	if vars["card.set"] != "PRM" || vars["title_font_size"] != "24" {
+		t.Errorf("got set %q and title_font_size %q, want the template defaults", vars["card.set"], vars["title_font_size"])
+	}
+}
+
+func TestRelatedReferences(t *testing.T) {
+	token := &metadata.Card{TCG: "mtg", Title: "Goblin", RulesText: "Haste {{card.title}}"}
+	card := &metadata.Card{TCG: "mtg", Title: "Raid", RulesText: "Create a {{related.title}} token. {{card.set|Promo}}", RelatedCard: token}
+
+	vars := NewVariableProcessor().BuildTemplateVariables(card, &templates.Template{})
+	if want := "Create a Goblin token. {{card.set|Promo}}"; vars["card.body"] != want {
+		t.Errorf("card.body = %q, want %q", vars["card.body"], want)
+	}
+	// The related card's own text isn't expanded
+	if want := "Haste {{card.title}}"; vars["related.card.body"] != want {
+		t.Errorf("related.card.body = %q, want %q", vars["related.card.body"], want)
+	}
+}
@@ -0,0 +1,65 @@
+package renderer
+
+import (
+	"strings"
+
+	"github.com/Merith-TK/tcg-cardgen/pkg/templates"
+)
+
+// defaultVariant names the layer drawn when no other variant in its group matches
+const defaultVariant = "default"
+
+// splitVariant splits a layer variant like "frame.rare" into group and value
+func splitVariant(variant string) (group, value string) {
+	if i := strings.LastIndex(variant, "."); i >= 0 {
+		return variant[:i], variant[i+1:]
+	}
+	return "", variant
+}
+
+// variantSelected reports whether a layer's variant matches the card's value of
+// the template's variant_field. Layers without a variant are always selected;
+// a "default" variant is selected when nothing else in its group matches.
+func (r *Renderer) variantSelected(layer templates.Layer, template *templates.Template, vars map[string]string) bool {
+	if layer.Variant == "" {
+		return true
+	}
+
+	selected := vars[template.VariantField]
+	group, value := splitVariant(layer.Variant)
+	if strings.EqualFold(value, selected) {
+		return true
+	}
+	if !strings.EqualFold(value, defaultVariant) {
+		return false
+	}
+
+	for _, other := range template.Layers {
+		otherGroup, otherValue := splitVariant(other.Variant)
+		if other.Variant != "" && otherGroup == group && strings.EqualFold(otherValue, selected) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectContent returns the text layer content for the card's value of the
+// layer's content_field: the matching content_cases entry, else its "default"
+// case, else the layer's content
+func selectContent(layer templates.Layer, vars map[string]string) string {
+	if layer.ContentField == "" || len(layer.ContentCases) == 0 {
+		return layer.Content
+	}
+
+	selected := strings.TrimSpace(vars[layer.ContentField])
+	fallback := layer.Content
+	for value, content := range layer.ContentCases {
+		if strings.EqualFold(value, selected) {
+			return content
+		}
+		if strings.EqualFold(value, defaultVariant) {
+			fallback = content
+		}
+	}
+	return fallback
+}
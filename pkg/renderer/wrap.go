@@ -0,0 +1,83 @@
+package renderer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wrapToken is a unit of text that line wrapping will not break apart
+type wrapToken struct {
+	Text        string
+	SpaceBefore bool // Whether a space separates this token from the previous one
+}
+
+// splitWrapTokens splits text into breakable tokens according to the break mode:
+//   - "word": break only on whitespace
+//   - "char": break between any two characters
+//   - "auto" (default): break on whitespace and between CJK characters
+func splitWrapTokens(text, breakMode string) []wrapToken {
+	var tokens []wrapToken
+
+	leadingSpace := text != "" && unicode.IsSpace([]rune(text)[0])
+
+	for i, word := range strings.Fields(text) {
+		// The first word only gets a separating space if the source had one,
+		// so formatting boundaries inside a word ("**3**/3", "Flying,") stay joined
+		spaceBefore := i > 0 || leadingSpace
+
+		switch breakMode {
+		case "word":
+			tokens = append(tokens, wrapToken{Text: word, SpaceBefore: spaceBefore})
+
+		case "char":
+			for j, r := range []rune(word) {
+				tokens = append(tokens, wrapToken{Text: string(r), SpaceBefore: j == 0 && spaceBefore})
+			}
+
+		default: // auto
+			for j, piece := range splitCJKRuns(word) {
+				tokens = append(tokens, wrapToken{Text: piece, SpaceBefore: j == 0 && spaceBefore})
+			}
+		}
+	}
+
+	return tokens
+}
+
+// splitCJKRuns splits a word so that each CJK character stands alone while
+// runs of other characters (Latin words, numbers) stay together
+func splitCJKRuns(word string) []string {
+	var pieces []string
+	var run strings.Builder
+
+	for _, r := range word {
+		if isCJK(r) {
+			if run.Len() > 0 {
+				pieces = append(pieces, run.String())
+				run.Reset()
+			}
+			pieces = append(pieces, string(r))
+			continue
+		}
+		run.WriteRune(r)
+	}
+
+	if run.Len() > 0 {
+		pieces = append(pieces, run.String())
+	}
+
+	return pieces
+}
+
+// isCJK reports whether a rune belongs to a script written without spaces
+func isCJK(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return true
+	case r >= 0x3000 && r <= 0x303F: // CJK symbols and punctuation
+		return true
+	case r >= 0xFF00 && r <= 0xFFEF: // Halfwidth and fullwidth forms
+		return true
+	}
+	return false
+}
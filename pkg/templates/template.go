@@ -3,11 +3,13 @@ package templates
 import (
 	"embed"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/Merith-TK/tcg-cardgen/pkg/metadata"
+	"github.com/Merith-TK/tcg-cardgen/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,26 +18,40 @@ import (
 //go:embed templates/*
 var builtinTemplates embed.FS
 
+// builtinPrefix marks an extends path that refers to an embedded template
+const builtinPrefix = "builtin:"
+
 // Template represents a card template definition
 type Template struct {
-	Name        string                 `yaml:"name"`
-	TCG         string                 `yaml:"tcg"`
-	Version     string                 `yaml:"version"`
-	Description string                 `yaml:"description"`
-	Extends     string                 `yaml:"extends,omitempty"` // Path to base template
-	Dimensions  Dimensions             `yaml:"dimensions"`
-	Layers      []Layer                `yaml:"layers"`
-	Required    []string               `yaml:"required_fields"`
-	Optional    map[string]interface{} `yaml:"optional_fields"`
-	Icons       map[string]string      `yaml:"icons"`
-	StyleTokens map[string]string      `yaml:"style_tokens"`                // Visual constants
-	Overrides   []LayerOverride        `yaml:"overrides,omitempty"`         // Layer modifications
-	AddLayers   []Layer                `yaml:"additional_layers,omitempty"` // Extra layers
-	Conditions  []Condition            `yaml:"conditions,omitempty"`        // Conditional includes
+	Name         string                       `yaml:"name"`
+	TCG          string                       `yaml:"tcg"`
+	Version      string                       `yaml:"version"`
+	Description  string                       `yaml:"description"`
+	Extends      string                       `yaml:"extends,omitempty"` // Path to base template
+	Default      bool                         `yaml:"default,omitempty"` // Used for cards of this TCG that don't name a cardstyle
+	Dimensions   Dimensions                   `yaml:"dimensions"`
+	Layers       []Layer                      `yaml:"layers"`
+	Required     []string                     `yaml:"required_fields"`
+	Optional     map[string]interface{}       `yaml:"optional_fields"`
+	Icons        map[string]string            `yaml:"icons"`
+	StyleTokens  map[string]string            `yaml:"style_tokens"`                // Visual constants
+	Overrides    []LayerOverride              `yaml:"overrides,omitempty"`         // Layer modifications
+	AddLayers    []Layer                      `yaml:"additional_layers,omitempty"` // Extra layers
+	Conditions   []Condition                  `yaml:"conditions,omitempty"`        // Conditional includes
+	Collector    CollectorLine                `yaml:"collector,omitempty"`         // card.collector composition
+	Background   string                       `yaml:"background,omitempty"`        // "transparent", a hex color or an image path (default white)
+	Keywords     []string                     `yaml:"keywords,omitempty"`          // Ability keywords bolded at the start of rules lines
+	DefaultFont  *Font                        `yaml:"default_font,omitempty"`      // Font fields used where a text layer leaves them unset
+	VariantField string                       `yaml:"variant_field,omitempty"`     // Variable selecting layer variants, e.g. card.rarity
+	Footer       FooterRule                   `yaml:"footer,omitempty"`            // Where card.footer starts in the card body
+	Regions      map[string]Region            `yaml:"regions,omitempty"`           // Named regions layers can use via region_ref
+	Maps         map[string]map[string]string `yaml:"maps,omitempty"`              // Lookup tables for {{map:name:variable}}, e.g. colors by rarity
 
 	// Runtime info
 	TemplateDir  string    `yaml:"-"`
 	BaseTemplate *Template `yaml:"-"` // Resolved base template
+	Warnings     []string  `yaml:"-"` // Non-fatal problems found while loading/merging
+	IconDirs     []string  `yaml:"-"` // Icon directories searched in order for {{icon_dir}} paths
 }
 
 // LayerOverride represents modifications to existing layers
@@ -50,28 +66,82 @@ type Condition struct {
 	Include string `yaml:"include"` // Template file to include
 }
 
+// FooterRule configures how the card body is split into card.body and
+// card.footer. With nothing set, a "## Footer" header starts the footer.
+type FooterRule struct {
+	Headers   []string `yaml:"headers,omitempty"`    // Header lines starting the footer, e.g. "## Flavor" (case insensitive)
+	Marker    string   `yaml:"marker,omitempty"`     // Line starting the footer, e.g. "%% footer"
+	LastBlock bool     `yaml:"last_block,omitempty"` // Use the block after the last "---" when no header or marker is found
+}
+
+// CollectorLine configures the computed card.collector variable
+type CollectorLine struct {
+	Format    string `yaml:"format,omitempty"`    // Components joined by the separator
+	Separator string `yaml:"separator,omitempty"` // Defaults to " • "
+}
+
 // Dimensions defines the output image dimensions
 type Dimensions struct {
-	Width  int `yaml:"width"`
-	Height int `yaml:"height"`
-	DPI    int `yaml:"dpi"`
+	Width      int     `yaml:"width"`
+	Height     int     `yaml:"height"`
+	DPI        int     `yaml:"dpi"`
+	Bleed      float64 `yaml:"bleed,omitempty"`       // Bleed beyond the trim line, in inches
+	SafeMargin float64 `yaml:"safe_margin,omitempty"` // Safe zone inset from the trim line, in inches
 }
 
 // Layer represents a single layer in the card template
 type Layer struct {
-	Name         string `yaml:"name"`
-	Role         string `yaml:"role,omitempty"` // Semantic role (title, artwork, etc.)
-	Type         string `yaml:"type"`           // "image", "text"
-	Source       string `yaml:"source,omitempty"`
-	Content      string `yaml:"content,omitempty"`
-	Region       Region `yaml:"region"`
-	Font         *Font  `yaml:"font,omitempty"`
-	FitMode      string `yaml:"fit_mode,omitempty"` // Image fit mode: "fill", "fit", "stretch", "center"
-	IconReplace  bool   `yaml:"icon_replace,omitempty"`
-	StripHeaders bool   `yaml:"strip_headers,omitempty"`
-	Condition    string `yaml:"condition,omitempty"`
-	Align        string `yaml:"align,omitempty"`
-	Fallback     string `yaml:"fallback,omitempty"`
+	Name           string   `yaml:"name"`
+	Role           string   `yaml:"role,omitempty"` // Semantic role (title, artwork, etc.)
+	Type           string   `yaml:"type"`           // "image", "text", "pips"
+	Source         string   `yaml:"source,omitempty"`
+	Sources        []string `yaml:"sources,omitempty"` // Extra candidate sources tried in order before fallback
+	Content        string   `yaml:"content,omitempty"`
+	Region         Region   `yaml:"region"`
+	RegionRef      string   `yaml:"region_ref,omitempty"` // Name of a template region used instead of region
+	Font           *Font    `yaml:"font,omitempty"`
+	FitMode        string   `yaml:"fit_mode,omitempty"`   // Image fit mode: "fill", "fit", "stretch", "center"
+	Brightness     *float64 `yaml:"brightness,omitempty"` // Image tone adjustments; 1.0 (or unset) = unchanged
+	Contrast       *float64 `yaml:"contrast,omitempty"`
+	Saturation     *float64 `yaml:"saturation,omitempty"` // 0 = grayscale
+	AutoTrim       bool     `yaml:"auto_trim,omitempty"`  // Crop transparent (or trim_color) borders before fitting
+	TrimColor      string   `yaml:"trim_color,omitempty"` // Solid background color auto_trim also crops
+	IconReplace    bool     `yaml:"icon_replace,omitempty"`
+	StripHeaders   bool     `yaml:"strip_headers,omitempty"`
+	BoldKeywords   bool     `yaml:"bold_keywords,omitempty"`   // Bold the template's keywords at the start of lines
+	ReminderItalic bool     `yaml:"reminder_italic,omitempty"` // Italicize parenthesized reminder text
+	Condition      string   `yaml:"condition,omitempty"`
+	Variant        string   `yaml:"variant,omitempty"` // "group.value": drawn only when variant_field equals value
+	Align          string   `yaml:"align,omitempty"`
+	Fallback       string   `yaml:"fallback,omitempty"`
+	BreakMode      string   `yaml:"break_mode,omitempty"`    // Line breaking: "word", "char", "auto" (default)
+	Raw            bool     `yaml:"raw,omitempty"`           // Draw text verbatim in a monospace font: no markdown, wrapping or icons
+	OverflowInto   string   `yaml:"overflow_into,omitempty"` // Text layer that continues lines this one can't fit
+	Columns        int      `yaml:"columns,omitempty"`       // Flow text down this many equal columns, left to right
+	ColumnGap      *float64 `yaml:"column_gap,omitempty"`    // Gutter between columns in pixels (default 20; 0 = none)
+
+	// Text layers: content_cases picks the content by the value of
+	// content_field, using its "default" case (then content) when none match
+	ContentField string            `yaml:"content_field,omitempty"` // Variable to switch on, e.g. card.tapped
+	ContentCases map[string]string `yaml:"content_cases,omitempty"` // Content keyed by the field's value
+
+	// Text nudges that leave wrapping alone: offsets move the drawn text and
+	// first_line_indent shifts the first line of each paragraph, in pixels
+	OffsetX         float64 `yaml:"offset_x,omitempty"`
+	OffsetY         float64 `yaml:"offset_y,omitempty"`
+	FirstLineIndent float64 `yaml:"first_line_indent,omitempty"`
+
+	// Pips layers: max circles across the region, the first count filled
+	Count      string `yaml:"count,omitempty"`       // Number of filled pips (variables allowed)
+	Max        string `yaml:"max,omitempty"`         // Total number of pips (variables allowed)
+	Color      string `yaml:"color,omitempty"`       // Filled pip color (default black)
+	EmptyColor string `yaml:"empty_color,omitempty"` // Empty pip fill; unset draws an outline in color
+
+	// Image layers: draw one cell of a sprite sheet split into a grid
+	SourceSheet string `yaml:"source_sheet,omitempty"` // Sheet image, tried before source
+	SheetCols   int    `yaml:"sheet_cols,omitempty"`   // Grid columns (default 1)
+	SheetRows   int    `yaml:"sheet_rows,omitempty"`   // Grid rows (default 1)
+	SheetIndex  string `yaml:"sheet_index,omitempty"`  // Zero-based cell, left to right then top to bottom
 }
 
 // Region defines a rectangular area on the card
@@ -89,71 +159,340 @@ type Font struct {
 	Weight string      `yaml:"weight,omitempty"`
 	Style  string      `yaml:"style,omitempty"`
 	Color  string      `yaml:"color"`
+
+	// Advanced fills painted through the text instead of Color
+	FillGradient *Gradient `yaml:"fill_gradient,omitempty"`
+	FillImage    string    `yaml:"fill_image,omitempty"` // Texture stretched over the drawn text
+}
+
+// Gradient defines a linear color gradient across a text fill
+type Gradient struct {
+	Colors []string `yaml:"colors"`          // Evenly spaced color stops
+	Angle  float64  `yaml:"angle,omitempty"` // Degrees; 0 runs left to right, 90 top to bottom
+}
+
+// LayerFont returns the font for a text layer. Fields the layer's font leaves
+// unset come from the template's default_font, then the 12pt black fallback.
+func (t *Template) LayerFont(layer Layer) *Font {
+	font := mergeFont(Font{Size: 12.0, Color: "#000000"}, t.DefaultFont)
+	font = mergeFont(font, layer.Font)
+	return &font
+}
+
+// mergeFont overlays the fields set in override onto base
+func mergeFont(base Font, override *Font) Font {
+	if override == nil {
+		return base
+	}
+
+	if override.Family != "" {
+		base.Family = override.Family
+	}
+	if override.Size != nil {
+		base.Size = override.Size
+	}
+	if override.Weight != "" {
+		base.Weight = override.Weight
+	}
+	if override.Style != "" {
+		base.Style = override.Style
+	}
+	if override.Color != "" {
+		base.Color = override.Color
+	}
+	if override.FillGradient != nil {
+		base.FillGradient = override.FillGradient
+	}
+	if override.FillImage != "" {
+		base.FillImage = override.FillImage
+	}
+	return base
 }
 
 // Manager handles template loading and management
 type Manager struct {
-	customTemplateDir  string
+	customTemplateDirs []string // Searched in order; earlier directories win
 	customCardstyleDir string
+	customFontDir      string
+	customIconDir      string
+	customTokensFile   string
+	sharedTokens       map[string]string // Tokens from the shared token files, loaded once
+	strict             bool
 	templates          map[string]*Template
+	cacheStats         types.CacheStats // Template lookups served from templates
 }
 
-// NewManager creates a new template manager
-func NewManager(customTemplateDir string) *Manager {
+// NewManager creates a new template manager that also searches the given
+// template directories, earlier ones first
+func NewManager(customTemplateDirs ...string) *Manager {
 	// Set up custom cardstyle directory
 	homeDir, _ := os.UserHomeDir()
 	customCardstyleDir := filepath.Join(homeDir, ".tcg-cardgen", "cardstyles")
+	customFontDir := filepath.Join(homeDir, ".tcg-cardgen", "fonts")
+	customIconDir := filepath.Join(homeDir, ".tcg-cardgen", "icons")
+	customTokensFile := filepath.Join(homeDir, ".tcg-cardgen", "tokens.yaml")
 
 	return &Manager{
-		customTemplateDir:  customTemplateDir,
+		customTemplateDirs: customTemplateDirs,
 		customCardstyleDir: customCardstyleDir,
+		customFontDir:      customFontDir,
+		customIconDir:      customIconDir,
+		customTokensFile:   customTokensFile,
 		templates:          make(map[string]*Template),
 	}
 }
 
-// LoadTemplate loads a template by TCG and cardstyle name
+// SetStrict makes template problems that are normally warnings (such as
+// duplicate layer names) fail template loading instead
+func (m *Manager) SetStrict(strict bool) {
+	m.strict = strict
+}
+
+// CacheStats returns how many template loads were served from the cache
+func (m *Manager) CacheStats() types.CacheStats {
+	return m.cacheStats
+}
+
+// FontDirs returns the global font directories in load order.
+// Later directories take priority, so workspace fonts override user fonts.
+func (m *Manager) FontDirs() []string {
+	return []string{
+		m.customFontDir, // User fonts: $HOME/.tcg-cardgen/fonts
+		".tcg-fonts",    // Workspace fonts, older name still read
+		"fonts",         // Workspace fonts (project-specific)
+	}
+}
+
+// LoadTemplate loads a template by TCG and cardstyle name.
+// An empty cardstyle loads the TCG's default cardstyle.
 func (m *Manager) LoadTemplate(tcg, cardstyle string) (*Template, error) {
+	if cardstyle == "" {
+		defaultStyle, err := m.DefaultCardstyle(tcg)
+		if err != nil {
+			return nil, err
+		}
+		cardstyle = defaultStyle
+	}
+
 	key := fmt.Sprintf("%s/%s", tcg, cardstyle)
 
 	// Check cache first
 	if template, exists := m.templates[key]; exists {
+		m.cacheStats.Hits++
 		return template, nil
 	}
+	m.cacheStats.Misses++
 
 	template, err := m.findAndLoadTemplate(tcg, cardstyle)
 	if err != nil {
-		return nil, fmt.Errorf("cardstyle %s/%s not found: %v", tcg, cardstyle, err)
+		return nil, fmt.Errorf("cardstyle %s/%s: %v", tcg, cardstyle, err)
+	}
+
+	if err := m.finishTemplate(template, "cardstyle "+key); err != nil {
+		return nil, err
 	}
 
 	m.templates[key] = template
 	return template, nil
 }
 
+// LoadTemplateFile loads a template straight from a file, resolving its
+// extends chain, for cards that name their own template instead of a cardstyle
+func (m *Manager) LoadTemplateFile(filePath string) (*Template, error) {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		filePath = abs
+	}
+	key := "file:" + filePath
+
+	if template, exists := m.templates[key]; exists {
+		m.cacheStats.Hits++
+		return template, nil
+	}
+	m.cacheStats.Misses++
+
+	template, err := m.loadAndProcessTemplate(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %v", filePath, err)
+	}
+
+	if err := m.finishTemplate(template, "template "+filePath); err != nil {
+		return nil, err
+	}
+
+	m.templates[key] = template
+	return template, nil
+}
+
+// finishTemplate checks a loaded template and applies shared tokens and icon
+// directories. Problems are prefixed with label to say which template has them.
+func (m *Manager) finishTemplate(template *Template, label string) error {
+	// Report layers that collide by name in the final (merged) layer list
+	for _, name := range duplicateLayerNames(template.Layers) {
+		msg := fmt.Sprintf("%s: duplicate layer name '%s' in '%s'", label, name, template.Name)
+		if m.strict {
+			return fmt.Errorf("%s", msg)
+		}
+		template.Warnings = append(template.Warnings, msg)
+	}
+
+	if err := resolveRegionRefs(template.Layers, template.Regions); err != nil {
+		return fmt.Errorf("%s: %v", label, err)
+	}
+
+	if err := template.ValidateGeometry(); err != nil {
+		return fmt.Errorf("%s: %v", label, err)
+	}
+
+	// Report overflow_into targets that can never receive the spilled text
+	for _, problem := range overflowProblems(template.Layers) {
+		template.Warnings = append(template.Warnings, fmt.Sprintf("%s: %s", label, problem))
+	}
+
+	sharedTokens, err := m.loadSharedTokens()
+	if err != nil {
+		return err
+	}
+	mergeStyleTokens(template, sharedTokens)
+	applyDefaultStyleTokens(template)
+	template.IconDirs = m.iconDirsFor(template)
+	return nil
+}
+
+// DefaultCardstyle returns the cardstyle used for cards of a TCG that don't
+// name one: a "default" cardstyle, or one whose template sets `default: true`.
+// Locations are searched in the usual priority order.
+func (m *Manager) DefaultCardstyle(tcg string) (string, error) {
+	cardstyles, err := m.ListAvailableCardstyles()
+	if err != nil {
+		return "", err
+	}
+
+	for _, style := range cardstyles {
+		if style.TCG == tcg && style.Default {
+			return style.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no cardstyle given and TCG %s has no default cardstyle", tcg)
+}
+
+// iconDirsFor lists the icon directories for a template: its own, then each
+// base template's in inheritance order, then the user icons directory.
+// Individual icons can be overridden without copying the whole set.
+func (m *Manager) iconDirsFor(template *Template) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for t := template; t != nil; t = t.BaseTemplate {
+		dir := filepath.Join(t.TemplateDir, "icons")
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	if m.customIconDir != "" && !seen[m.customIconDir] {
+		dirs = append(dirs, m.customIconDir)
+	}
+	return dirs
+}
+
+// defaultRarityColors maps card.rarity to the color {{rarity_color}} resolves to.
+// Templates override individual entries with style_tokens like "rarity_color.rare".
+var defaultRarityColors = map[string]string{
+	"common":   "#000000",
+	"uncommon": "#c0c0c0",
+	"rare":     "#d4af37",
+	"mythic":   "#e66a1e",
+}
+
+// sharedTokenFiles lists the style token files shared by every cardstyle,
+// lowest priority first
+func (m *Manager) sharedTokenFiles() []string {
+	return []string{
+		m.customTokensFile, // User tokens: $HOME/.tcg-cardgen/tokens.yaml
+		filepath.Join(".tcg-cardstyles", "tokens.yaml"), // Workspace tokens (project-specific)
+	}
+}
+
+// loadSharedTokens reads the shared token files once. Later files override
+// earlier ones; missing files are skipped.
+func (m *Manager) loadSharedTokens() (map[string]string, error) {
+	if m.sharedTokens != nil {
+		return m.sharedTokens, nil
+	}
+
+	tokens := make(map[string]string)
+	for _, path := range m.sharedTokenFiles() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var fileTokens map[string]string
+		if err := yaml.Unmarshal(data, &fileTokens); err != nil {
+			return nil, fmt.Errorf("error parsing style tokens %s: %v", path, err)
+		}
+		for key, value := range fileTokens {
+			tokens[key] = value
+		}
+	}
+
+	m.sharedTokens = tokens
+	return tokens, nil
+}
+
+// mergeStyleTokens adds tokens the template doesn't define itself
+func mergeStyleTokens(template *Template, tokens map[string]string) {
+	if template.StyleTokens == nil {
+		template.StyleTokens = make(map[string]string)
+	}
+	for key, value := range tokens {
+		if _, exists := template.StyleTokens[key]; !exists {
+			template.StyleTokens[key] = value
+		}
+	}
+}
+
+// applyDefaultStyleTokens adds built-in style tokens the template doesn't define
+func applyDefaultStyleTokens(template *Template) {
+	if template.StyleTokens == nil {
+		template.StyleTokens = make(map[string]string)
+	}
+	for rarity, color := range defaultRarityColors {
+		key := "rarity_color." + rarity
+		if _, exists := template.StyleTokens[key]; !exists {
+			template.StyleTokens[key] = color
+		}
+	}
+}
+
 // findAndLoadTemplate searches for a template in various locations
 func (m *Manager) findAndLoadTemplate(tcg, cardstyle string) (*Template, error) {
 	// Search order (first found gets priority):
 	// 1. Workspace cardstyles: templates/tcg/cardstyle.yaml (project-specific)
 	// 2. User cardstyles: $HOME/.tcg-cardgen/cardstyles/tcg/cardstyle.yaml
 	// 3. User cardstyles: $HOME/.tcg-cardgen/cardstyles/cardstyle.yaml (with TCG metadata check)
-	// 4. Legacy custom template dir: custom-dir/tcg/cardstyle.yaml (for backwards compatibility)
+	// 4. Legacy custom template dirs: custom-dir/tcg/cardstyle.yaml, in the order given
 	// 5. Embedded templates: templates/tcg/cardstyle.yaml (final fallback)
 
 	// 1. Workspace templates directory (project-specific cardstyles)
 	workspacePath := filepath.Join(".tcg-cardstyles", tcg, cardstyle+".yaml")
-	if template, err := m.loadAndProcessTemplate(workspacePath); err == nil {
-		return template, nil
+	if template, found, err := m.loadTemplateIfExists(workspacePath); found {
+		return template, err
 	}
 
 	// 2. TCG-specific folder in user cardstyles
 	if m.customCardstyleDir != "" {
 		tcgPath := filepath.Join(m.customCardstyleDir, tcg, cardstyle+".yaml")
-		if template, err := m.loadAndProcessTemplate(tcgPath); err == nil {
-			return template, nil
+		if template, found, err := m.loadTemplateIfExists(tcgPath); found {
+			return template, err
 		}
 
 		// 3. Root level in user cardstyles (check TCG metadata)
 		rootPath := filepath.Join(m.customCardstyleDir, cardstyle+".yaml")
-		if template, err := m.loadAndProcessTemplate(rootPath); err == nil {
+		if template, found, err := m.loadTemplateIfExists(rootPath); found {
+			if err != nil {
+				return nil, err
+			}
 			// Verify TCG matches
 			if template.TCG == tcg {
 				return template, nil
@@ -161,11 +500,11 @@ func (m *Manager) findAndLoadTemplate(tcg, cardstyle string) (*Template, error)
 		}
 	}
 
-	// 4. Legacy custom template directory (for backwards compatibility)
-	if m.customTemplateDir != "" {
-		templatePath := filepath.Join(m.customTemplateDir, tcg, cardstyle+".yaml")
-		if template, err := m.loadAndProcessTemplate(templatePath); err == nil {
-			return template, nil
+	// 4. Legacy custom template directories (earlier directories win)
+	for _, dir := range m.customTemplateDirs {
+		templatePath := filepath.Join(dir, tcg, cardstyle+".yaml")
+		if template, found, err := m.loadTemplateIfExists(templatePath); found {
+			return template, err
 		}
 	}
 
@@ -242,6 +581,17 @@ func (m *Manager) resolveBuiltinBaseTemplate(extendsPath, currentDir string) (*T
 	return &template, nil
 }
 
+// resolveBuiltinReference loads a builtin template from a "tcg/style" reference
+func (m *Manager) resolveBuiltinReference(ref string) (*Template, error) {
+	ref = strings.TrimSuffix(strings.TrimSpace(ref), ".yaml")
+	tcg, cardstyle, found := strings.Cut(ref, "/")
+	if !found || tcg == "" || cardstyle == "" {
+		return nil, fmt.Errorf("invalid builtin reference '%s%s', expected %stcg/style", builtinPrefix, ref, builtinPrefix)
+	}
+
+	return m.loadBuiltinTemplate(tcg, cardstyle)
+}
+
 // loadTemplateFile loads a template from a file
 func (m *Manager) loadTemplateFile(filePath string) (*Template, error) {
 	data, err := os.ReadFile(filePath)
@@ -258,6 +608,22 @@ func (m *Manager) loadTemplateFile(filePath string) (*Template, error) {
 	return &template, nil
 }
 
+// loadTemplateIfExists loads a template file if it exists. A missing file
+// reports found as false so the search moves on, but a file that exists and
+// fails to load (bad YAML, an undefined alias, a missing base) is an error
+// rather than being silently skipped.
+func (m *Manager) loadTemplateIfExists(filePath string) (*Template, bool, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, false, nil
+	}
+
+	template, err := m.loadAndProcessTemplate(filePath)
+	if err != nil {
+		return nil, true, fmt.Errorf("%s: %v", filePath, err)
+	}
+	return template, true, nil
+}
+
 // loadAndProcessTemplate loads a template and handles inheritance
 func (m *Manager) loadAndProcessTemplate(filePath string) (*Template, error) {
 	// Load the base template
@@ -282,6 +648,11 @@ func (m *Manager) loadAndProcessTemplate(filePath string) (*Template, error) {
 
 // resolveBaseTemplate resolves the path to a base template
 func (m *Manager) resolveBaseTemplate(extendsPath, currentDir string) (*Template, error) {
+	// "builtin:tcg/style" extends a template shipped inside the binary
+	if strings.HasPrefix(extendsPath, builtinPrefix) {
+		return m.resolveBuiltinReference(strings.TrimPrefix(extendsPath, builtinPrefix))
+	}
+
 	var basePath string
 
 	// Handle relative paths
@@ -300,10 +671,35 @@ func (m *Manager) mergeTemplates(base, extended *Template) *Template {
 	// Start with a copy of the extended template
 	result := *extended
 	result.BaseTemplate = base
+	result.Warnings = append(append([]string{}, base.Warnings...), extended.Warnings...)
 
-	// Merge dimensions if not set in extended
-	if result.Dimensions.Width == 0 {
-		result.Dimensions = base.Dimensions
+	// Merge dimensions field by field, so setting only the height keeps the base width
+	result.Dimensions = mergeDimensions(base.Dimensions, extended.Dimensions)
+
+	// Merge default font field by field (extended wins)
+	if base.DefaultFont != nil {
+		merged := mergeFont(*base.DefaultFont, extended.DefaultFont)
+		result.DefaultFont = &merged
+	}
+
+	// Inherit the variant selector if not set in extended
+	if result.VariantField == "" {
+		result.VariantField = base.VariantField
+	}
+
+	// Inherit background if not set in extended
+	if result.Background == "" {
+		result.Background = base.Background
+	}
+
+	// Inherit the footer rule if the extended template doesn't set one
+	if len(result.Footer.Headers) == 0 && result.Footer.Marker == "" && !result.Footer.LastBlock {
+		result.Footer = base.Footer
+	}
+
+	// Inherit collector line format if not set in extended
+	if result.Collector.Format == "" {
+		result.Collector = base.Collector
 	}
 
 	// Merge required fields (base + extended)
@@ -339,6 +735,16 @@ func (m *Manager) mergeTemplates(base, extended *Template) *Template {
 		}
 	}
 
+	// Merge keywords (base + extended)
+	seenKeywords := make(map[string]bool)
+	result.Keywords = nil
+	for _, keyword := range append(append([]string{}, base.Keywords...), extended.Keywords...) {
+		if key := strings.ToLower(keyword); !seenKeywords[key] {
+			seenKeywords[key] = true
+			result.Keywords = append(result.Keywords, keyword)
+		}
+	}
+
 	// Merge icons (base defaults, extended overrides)
 	if result.Icons == nil {
 		result.Icons = make(map[string]string)
@@ -349,9 +755,35 @@ func (m *Manager) mergeTemplates(base, extended *Template) *Template {
 		}
 	}
 
-	// Handle layers - extended layers come after base layers, but can override by name
+	// Merge lookup maps entry by entry (base defaults, extended overrides)
+	result.Maps = make(map[string]map[string]string)
+	for _, maps := range []map[string]map[string]string{base.Maps, extended.Maps} {
+		for name, entries := range maps {
+			if result.Maps[name] == nil {
+				result.Maps[name] = make(map[string]string)
+			}
+			for key, value := range entries {
+				result.Maps[name][key] = value
+			}
+		}
+	}
+
+	// Merge named regions (base defaults, extended overrides)
+	result.Regions = make(map[string]Region)
+	for name, region := range base.Regions {
+		result.Regions[name] = region
+	}
+	for name, region := range extended.Regions {
+		result.Regions[name] = region
+	}
+
+	// Handle layers - extended layers come after base layers, but can override by name.
+	// Base layers take the merged regions first, so overrides adjust real coordinates.
 	baseLayers := make(map[string]Layer)
 	for _, layer := range base.Layers {
+		if region, exists := result.Regions[layer.RegionRef]; exists && layer.RegionRef != "" {
+			layer.Region = region
+		}
 		baseLayers[layer.Name] = layer
 	}
 
@@ -359,7 +791,12 @@ func (m *Manager) mergeTemplates(base, extended *Template) *Template {
 	for _, override := range result.Overrides {
 		if baseLayer, exists := baseLayers[override.Layer]; exists {
 			// Apply override to base layer
-			modifiedLayer := m.applyLayerOverride(baseLayer, override)
+			modifiedLayer, err := m.applyLayerOverride(baseLayer, override)
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf(
+					"override for layer '%s' in '%s' is ignored: %v", override.Layer, extended.Name, err))
+				continue
+			}
 			baseLayers[override.Layer] = modifiedLayer
 		}
 	}
@@ -380,6 +817,10 @@ func (m *Manager) mergeTemplates(base, extended *Template) *Template {
 	for _, layer := range extended.Layers {
 		if !layerNames[layer.Name] {
 			finalLayers = append(finalLayers, layer)
+		} else {
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"layer '%s' in '%s' is ignored because base template '%s' already defines it (use overrides instead)",
+				layer.Name, extended.Name, base.Name))
 		}
 	}
 
@@ -390,35 +831,223 @@ func (m *Manager) mergeTemplates(base, extended *Template) *Template {
 	return &result
 }
 
-// applyLayerOverride applies override settings to a layer
-func (m *Manager) applyLayerOverride(layer Layer, override LayerOverride) Layer {
-	// This is a simplified implementation - in practice you'd want to handle
-	// field-specific merging for complex nested structures
-	modified := layer
+// resolveRegionRefs sets the region of every layer with a region_ref to the
+// named region
+func resolveRegionRefs(layers []Layer, regions map[string]Region) error {
+	for i, layer := range layers {
+		if layer.RegionRef == "" {
+			continue
+		}
+		region, exists := regions[layer.RegionRef]
+		if !exists {
+			return fmt.Errorf("layer '%s' uses undefined region_ref '%s'", layer.Name, layer.RegionRef)
+		}
+		layers[i].Region = region
+	}
+	return nil
+}
 
-	for key, value := range override.Updates {
-		switch key {
-		case "source":
-			if str, ok := value.(string); ok {
-				modified.Source = str
-			}
-		case "content":
-			if str, ok := value.(string); ok {
-				modified.Content = str
-			}
-		case "condition":
-			if str, ok := value.(string); ok {
-				modified.Condition = str
-			}
-		case "fit_mode":
-			if str, ok := value.(string); ok {
-				modified.FitMode = str
+// overflowProblems describes overflow_into targets that don't exist, aren't
+// text layers, or are drawn before the layer spilling into them
+func overflowProblems(layers []Layer) []string {
+	var problems []string
+	for i, layer := range layers {
+		if layer.OverflowInto == "" {
+			continue
+		}
+
+		target := -1
+		for j, candidate := range layers {
+			if candidate.Name == layer.OverflowInto {
+				target = j
+				break
 			}
-			// Add more field overrides as needed
+		}
+
+		switch {
+		case layer.Raw:
+			problems = append(problems, fmt.Sprintf("layer '%s' is raw, so its text can't overflow into '%s'", layer.Name, layer.OverflowInto))
+		case target < 0:
+			problems = append(problems, fmt.Sprintf("layer '%s' overflows into unknown layer '%s'", layer.Name, layer.OverflowInto))
+		case layers[target].Type != "text":
+			problems = append(problems, fmt.Sprintf("layer '%s' overflows into '%s', which is not a text layer", layer.Name, layer.OverflowInto))
+		case target <= i:
+			problems = append(problems, fmt.Sprintf("layer '%s' overflows into '%s', which is drawn before it", layer.Name, layer.OverflowInto))
+		case layers[target].Raw:
+			problems = append(problems, fmt.Sprintf("layer '%s' overflows into '%s', which is a raw layer", layer.Name, layer.OverflowInto))
 		}
 	}
+	return problems
+}
 
-	return modified
+// mergeDimensions fills the fields the extended template leaves unset from the base
+func mergeDimensions(base, extended Dimensions) Dimensions {
+	merged := extended
+	if merged.Width == 0 {
+		merged.Width = base.Width
+	}
+	if merged.Height == 0 {
+		merged.Height = base.Height
+	}
+	if merged.DPI == 0 {
+		merged.DPI = base.DPI
+	}
+	if merged.Bleed == 0 {
+		merged.Bleed = base.Bleed
+	}
+	if merged.SafeMargin == 0 {
+		merged.SafeMargin = base.SafeMargin
+	}
+	return merged
+}
+
+// ValidateGeometry checks that the card and every layer region have a
+// positive size, so a broken template fails with a message naming the
+// problem instead of rendering garbage or panicking
+func (t *Template) ValidateGeometry() error {
+	if t.Dimensions.Width <= 0 || t.Dimensions.Height <= 0 {
+		return fmt.Errorf("template '%s' has invalid dimensions %dx%d: width and height must be positive",
+			t.Name, t.Dimensions.Width, t.Dimensions.Height)
+	}
+	for _, layer := range t.Layers {
+		if layer.Region.Width <= 0 || layer.Region.Height <= 0 {
+			return fmt.Errorf("layer '%s' in template '%s' has invalid region size %dx%d: width and height must be positive",
+				layer.Name, t.Name, layer.Region.Width, layer.Region.Height)
+		}
+		if layer.Columns < 0 {
+			return fmt.Errorf("layer '%s' in template '%s' has %d columns: columns must be positive",
+				layer.Name, t.Name, layer.Columns)
+		}
+		if layer.ColumnGap != nil && *layer.ColumnGap < 0 {
+			return fmt.Errorf("layer '%s' in template '%s' has a column_gap of %g: the gap can't be negative",
+				layer.Name, t.Name, *layer.ColumnGap)
+		}
+		if layer.Columns > 1 && layer.ColumnWidth() <= 0 {
+			return fmt.Errorf("layer '%s' in template '%s' is too narrow for %d columns with a %.0fpx gap",
+				layer.Name, t.Name, layer.Columns, layer.Gap())
+		}
+	}
+	return nil
+}
+
+// Scaled returns a copy of the template with its dimensions, DPI and layer
+// geometry (regions, offsets and indents) multiplied by factor. Font sizes are
+// left as written so variable and relative sizes can be scaled once resolved.
+func (t *Template) Scaled(factor float64) *Template {
+	scaled := *t
+	scaled.Dimensions.Width = scaleInt(t.Dimensions.Width, factor)
+	scaled.Dimensions.Height = scaleInt(t.Dimensions.Height, factor)
+	scaled.Dimensions.DPI = scaleInt(t.Dimensions.DPI, factor)
+
+	scaled.Layers = make([]Layer, len(t.Layers))
+	for i, layer := range t.Layers {
+		// Scale edges rather than sizes so adjoining regions stay flush
+		region := layer.Region
+		layer.Region.X = scaleInt(region.X, factor)
+		layer.Region.Y = scaleInt(region.Y, factor)
+		layer.Region.Width = scaleInt(region.X+region.Width, factor) - layer.Region.X
+		layer.Region.Height = scaleInt(region.Y+region.Height, factor) - layer.Region.Y
+
+		layer.OffsetX *= factor
+		layer.OffsetY *= factor
+		layer.FirstLineIndent *= factor
+		if layer.ColumnGap != nil || layer.Columns > 1 {
+			gap := layer.Gap() * factor // A new value so the original template keeps its gap
+			layer.ColumnGap = &gap
+		}
+		scaled.Layers[i] = layer
+	}
+
+	return &scaled
+}
+
+// defaultColumnGap is the gutter between text columns when column_gap is unset
+const defaultColumnGap = 20
+
+// Gap returns the gutter between the layer's text columns
+func (l Layer) Gap() float64 {
+	if l.ColumnGap == nil {
+		return defaultColumnGap
+	}
+	return *l.ColumnGap
+}
+
+// ColumnWidth returns the width of one of the layer's text columns: the
+// region's width when it has a single column
+func (l Layer) ColumnWidth() float64 {
+	if l.Columns <= 1 {
+		return float64(l.Region.Width)
+	}
+	return (float64(l.Region.Width) - l.Gap()*float64(l.Columns-1)) / float64(l.Columns)
+}
+
+// scaleInt multiplies a pixel value by factor, rounding to the nearest pixel
+func scaleInt(value int, factor float64) int {
+	return int(math.Round(float64(value) * factor))
+}
+
+// duplicateLayerNames returns layer names that appear more than once, in order of first repeat
+func duplicateLayerNames(layers []Layer) []string {
+	seen := make(map[string]bool)
+	reported := make(map[string]bool)
+	var duplicates []string
+
+	for _, layer := range layers {
+		if seen[layer.Name] && !reported[layer.Name] {
+			duplicates = append(duplicates, layer.Name)
+			reported[layer.Name] = true
+		}
+		seen[layer.Name] = true
+	}
+
+	return duplicates
+}
+
+// applyLayerOverride applies an override's fields on top of a layer. Nested
+// maps such as region and font merge key by key, so an override can move one
+// coordinate or recolor a font; any other value replaces the layer's. Anchors
+// and aliases are resolved when the template is decoded, so aliased values
+// behave exactly like literal ones.
+func (m *Manager) applyLayerOverride(layer Layer, override LayerOverride) (Layer, error) {
+	data, err := yaml.Marshal(layer)
+	if err != nil {
+		return layer, err
+	}
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return layer, err
+	}
+
+	// A new region replaces the layer's named region
+	if _, setsRegion := override.Updates["region"]; setsRegion {
+		if _, setsRef := override.Updates["region_ref"]; !setsRef {
+			delete(fields, "region_ref")
+		}
+	}
+	mergeFields(fields, override.Updates)
+
+	data, err = yaml.Marshal(fields)
+	if err != nil {
+		return layer, err
+	}
+	var modified Layer
+	if err := yaml.Unmarshal(data, &modified); err != nil {
+		return layer, err
+	}
+	return modified, nil
+}
+
+// mergeFields copies updates into fields, merging nested maps recursively
+func mergeFields(fields, updates map[string]interface{}) {
+	for key, value := range updates {
+		existing, isMap := fields[key].(map[string]interface{})
+		update, updateIsMap := value.(map[string]interface{})
+		if isMap && updateIsMap {
+			mergeFields(existing, update)
+			continue
+		}
+		fields[key] = value
+	}
 }
 
 // ValidateCard validates a card against this template
@@ -505,16 +1134,9 @@ func (t *Template) hasNestedField(card *metadata.Card, section, field string) bo
 	return false
 }
 
-// CardStyleInfo represents information about a discovered cardstyle
-type CardStyleInfo struct {
-	TCG         string
-	Name        string
-	DisplayName string
-	Description string
-	Version     string
-	Source      string // "built-in" or path to custom cardstyle
-	Extends     string // Base template it extends
-}
+// CardStyleInfo represents information about a discovered cardstyle. It is
+// the shared types.CardStyleInfo, so discovery results need no conversion.
+type CardStyleInfo = types.CardStyleInfo
 
 // ListAvailableCardstyles discovers and lists all available cardstyles
 func (m *Manager) ListAvailableCardstyles() ([]CardStyleInfo, error) {
@@ -547,9 +1169,9 @@ func (m *Manager) ListAvailableCardstyles() ([]CardStyleInfo, error) {
 		}
 	}
 
-	// 3. Discover legacy custom templates (for backwards compatibility)
-	if m.customTemplateDir != "" {
-		legacyStyles, err := m.discoverLegacyTemplates()
+	// 3. Discover legacy custom templates, in directory priority order
+	for _, dir := range m.customTemplateDirs {
+		legacyStyles, err := m.discoverLegacyTemplates(dir)
 		if err == nil {
 			for _, style := range legacyStyles {
 				key := fmt.Sprintf("%s/%s", style.TCG, style.Name)
@@ -573,9 +1195,24 @@ func (m *Manager) ListAvailableCardstyles() ([]CardStyleInfo, error) {
 		}
 	}
 
+	markDefaultCardstyles(allCardstyles)
 	return allCardstyles, nil
 }
 
+// markDefaultCardstyles leaves Default set only on each TCG's effective default:
+// the first cardstyle named "default" or flagged `default: true`
+func markDefaultCardstyles(cardstyles []CardStyleInfo) {
+	found := make(map[string]bool)
+	for i := range cardstyles {
+		style := &cardstyles[i]
+		isDefault := !found[style.TCG] && (style.Name == "default" || style.Default)
+		if isDefault {
+			found[style.TCG] = true
+		}
+		style.Default = isDefault
+	}
+}
+
 // discoverEmbeddedCardstyles finds embedded built-in cardstyles
 func (m *Manager) discoverEmbeddedCardstyles() ([]CardStyleInfo, error) {
 	var cardstyles []CardStyleInfo
@@ -632,6 +1269,7 @@ func (m *Manager) discoverEmbeddedCardstyles() ([]CardStyleInfo, error) {
 				if template.Version != "" {
 					info.Version = template.Version
 				}
+				info.Default = template.Default
 			}
 
 			cardstyles = append(cardstyles, *info)
@@ -757,10 +1395,10 @@ func (m *Manager) discoverUserCardstyles() ([]CardStyleInfo, error) {
 }
 
 // discoverLegacyTemplates finds templates in legacy custom template directory
-func (m *Manager) discoverLegacyTemplates() ([]CardStyleInfo, error) {
+func (m *Manager) discoverLegacyTemplates(dir string) ([]CardStyleInfo, error) {
 	var cardstyles []CardStyleInfo
 
-	tcgDirs, err := os.ReadDir(m.customTemplateDir)
+	tcgDirs, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
@@ -771,7 +1409,7 @@ func (m *Manager) discoverLegacyTemplates() ([]CardStyleInfo, error) {
 		}
 
 		tcgName := tcgDir.Name()
-		tcgPath := filepath.Join(m.customTemplateDir, tcgName)
+		tcgPath := filepath.Join(dir, tcgName)
 
 		cardstyleFiles, err := os.ReadDir(tcgPath)
 		if err != nil {
@@ -811,6 +1449,7 @@ func (m *Manager) getCardstyleInfo(filePath, tcg, name, source string) (*CardSty
 		Version:     template.Version,
 		Source:      source,
 		Extends:     template.Extends,
+		Default:     template.Default,
 	}
 
 	if source != "built-in" {
@@ -0,0 +1,236 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeTemplates writes template files into a temp directory and returns it
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// findLayer returns the named layer
+func findLayer(t *testing.T, template *Template, name string) Layer {
+	t.Helper()
+
+	for _, layer := range template.Layers {
+		if layer.Name == name {
+			return layer
+		}
+	}
+	t.Fatalf("layer %s not found", name)
+	return Layer{}
+}
+
+// layerRegion returns the region of the named layer
+func layerRegion(t *testing.T, template *Template, name string) Region {
+	t.Helper()
+	return findLayer(t, template, name).Region
+}
+
+const regionsBase = `name: "base"
+tcg: "test"
+dimensions: { width: 300, height: 400 }
+regions:
+  art_box: { x: 20, y: 40, width: 260, height: 180 }
+layers:
+  - name: "art"
+    type: "image"
+    region_ref: "art_box"
+  - name: "art_border"
+    type: "image"
+    region_ref: "art_box"
+  - name: "title"
+    type: "text"
+    region: { x: 20, y: 10, width: 260, height: 24 }
+`
+
+func TestRegionRefs(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"base.yaml": regionsBase,
+		"moved.yaml": `name: "moved"
+tcg: "test"
+extends: "base.yaml"
+regions:
+  art_box: { x: 30, y: 50, width: 240, height: 160 }
+overrides:
+  - layer: "art_border"
+    region: { x: 25 }
+`,
+	})
+	manager := NewManager()
+
+	base, err := manager.LoadTemplateFile(filepath.Join(dir, "base.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load base: %v", err)
+	}
+	want := Region{X: 20, Y: 40, Width: 260, Height: 180}
+	if got := layerRegion(t, base, "art"); got != want {
+		t.Errorf("base art region = %+v, want %+v", got, want)
+	}
+
+	moved, err := manager.LoadTemplateFile(filepath.Join(dir, "moved.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load moved: %v", err)
+	}
+	// Redefining the region moves every inherited layer that refers to it
+	want = Region{X: 30, Y: 50, Width: 240, Height: 160}
+	if got := layerRegion(t, moved, "art"); got != want {
+		t.Errorf("moved art region = %+v, want %+v", got, want)
+	}
+	// An override nudges the resolved region and detaches it from the name
+	want = Region{X: 25, Y: 50, Width: 240, Height: 160}
+	if got := layerRegion(t, moved, "art_border"); got != want {
+		t.Errorf("overridden art_border region = %+v, want %+v", got, want)
+	}
+
+	// The base template is unchanged by its extension
+	if got := layerRegion(t, base, "art"); got.X != 20 {
+		t.Errorf("base art region changed to %+v", got)
+	}
+}
+
+func TestUndefinedRegionRef(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"broken.yaml": strings.ReplaceAll(regionsBase, `region_ref: "art_box"`, `region_ref: "missing"`),
+	})
+
+	_, err := NewManager().LoadTemplateFile(filepath.Join(dir, "broken.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "undefined region_ref 'missing'") {
+		t.Errorf("got error %v, want an undefined region_ref error", err)
+	}
+}
+
+func TestReferencedVariables(t *testing.T) {
+	var template Template
+	err := yaml.Unmarshal([]byte(`name: "refs"
+tcg: "test"
+dimensions: { width: 300, height: 400 }
+collector:
+  format: "{{pad:card.print_this:3}}/{{pad:card.print_total:3}}"
+layers:
+  - name: "title"
+    type: "text"
+    content: "{{card.title}}"
+    region: { x: 0, y: 0, width: 300, height: 30 }
+    font:
+      size: "{{title_size|24}}"
+      color: "{{map:title_colors:card.rarity}}"
+  - name: "subtitle"
+    type: "text"
+    content: "{{card.title}}, {{ card.subtitle | none }}"
+    condition: "{{card.title}} && {{mtg.legendary}}"
+    region: { x: 0, y: 30, width: 300, height: 30 }
+    font: { color: "{{style_tokens.ink}}" }
+  - name: "art"
+    type: "image"
+    source: "{{card.artwork}}"
+    fallback: "{{template_dir}}/art/{{card.{{mtg.color}}}}.png"
+    region: { x: 0, y: 60, width: 300, height: 200 }
+`), &template)
+	if err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+
+	// Repeated references are listed once, sorted, without transforms or defaults
+	want := []string{
+		"card.artwork", "card.print_this", "card.print_total", "card.rarity", "card.subtitle", "card.title",
+		"mtg.color", "mtg.legendary", "style_tokens.ink", "template_dir", "title_size",
+	}
+	if got := template.ReferencedVariables(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ReferencedVariables() = %v, want %v", got, want)
+	}
+}
+
+func TestAnchoredExample(t *testing.T) {
+	template, err := NewManager().LoadTemplateFile(filepath.Join("..", "..", "examples", "templates", "mtg", "anchored.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load anchored.yaml: %v", err)
+	}
+
+	// Each stat layer reuses the anchored region, two of them moved by a merge key
+	for name, x := range map[string]int{"stat_cost": 70, "stat_color": 280, "stat_rarity": 490} {
+		want := Region{X: x, Y: 860, Width: 190, Height: 40}
+		if got := layerRegion(t, template, name); got != want {
+			t.Errorf("%s region = %+v, want %+v", name, got, want)
+		}
+		if font := findLayer(t, template, name).Font; font == nil || font.Color != "{{style_tokens.color_stat}}" {
+			t.Errorf("%s font = %+v, want the anchored stat font", name, font)
+		}
+	}
+
+	// An aliased token resolves to its anchor's value
+	for _, token := range []string{"color_accent", "color_stat"} {
+		if got := template.StyleTokens[token]; got != "#8a1c1c" {
+			t.Errorf("style token %s = %q, want #8a1c1c", token, got)
+		}
+	}
+	if template.StyleTokens["font_text"] == "" {
+		t.Errorf("style token font_text is empty, want it inherited from mtg/basic")
+	}
+
+	// Overrides change only the fields they name
+	if want := (Region{X: 70, Y: 620, Width: 610, Height: 230}); layerRegion(t, template, "card_text") != want {
+		t.Errorf("card_text region = %+v, want %+v", layerRegion(t, template, "card_text"), want)
+	}
+	if font := findLayer(t, template, "type_line").Font; font == nil || font.Size != 18 || font.Color != "{{style_tokens.color_stat}}" {
+		t.Errorf("type_line font = %+v, want the aliased stat font", font)
+	}
+}
+
+func TestColumnGap(t *testing.T) {
+	columns := `name: "columns"
+tcg: "test"
+dimensions: { width: 300, height: 400 }
+layers:
+  - name: "rules"
+    type: "text"
+    region: { x: 0, y: 0, width: 200, height: 100 }
+    columns: 2
+`
+	tests := []struct {
+		gap   string
+		want  float64
+		width float64
+	}{
+		{"", 20, 90},
+		{"    column_gap: 0\n", 0, 100}, // Explicitly gutterless, not the default
+		{"    column_gap: 40\n", 40, 80},
+	}
+
+	manager := NewManager()
+	for _, test := range tests {
+		dir := writeTemplates(t, map[string]string{"columns.yaml": columns + test.gap})
+		template, err := manager.LoadTemplateFile(filepath.Join(dir, "columns.yaml"))
+		if err != nil {
+			t.Fatalf("failed to load template with %q: %v", test.gap, err)
+		}
+		layer := findLayer(t, template, "rules")
+		if layer.Gap() != test.want || layer.ColumnWidth() != test.width {
+			t.Errorf("%q: gap %v, column width %v, want %v and %v", test.gap, layer.Gap(), layer.ColumnWidth(), test.want, test.width)
+		}
+
+		// Scaling keeps a zero gap and leaves the original untouched
+		if scaled := findLayer(t, template.Scaled(2), "rules"); scaled.Gap() != test.want*2 || layer.Gap() != test.want {
+			t.Errorf("%q: scaled gap %v (original now %v), want %v", test.gap, scaled.Gap(), layer.Gap(), test.want*2)
+		}
+	}
+
+	dir := writeTemplates(t, map[string]string{"negative.yaml": columns + "    column_gap: -10\n"})
+	if _, err := manager.LoadTemplateFile(filepath.Join(dir, "negative.yaml")); err == nil || !strings.Contains(err.Error(), "can't be negative") {
+		t.Errorf("got error %v, want a negative column_gap error", err)
+	}
+}
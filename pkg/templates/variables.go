@@ -0,0 +1,94 @@
+package templates
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// variableReference matches the innermost {{...}} token, so nested references
+// such as {{card.{{key}}}} still yield the inner variable
+var variableReference = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// transformPrefix matches a transform token such as "pad:card.number:3"
+var transformPrefix = regexp.MustCompile(`^\w+:\s*([^:]+)`)
+
+// identifierPrefix matches the variable name at the start of a token
+var identifierPrefix = regexp.MustCompile(`^[A-Za-z_][\w.]*`)
+
+// ReferencedVariables returns the unique, sorted names of every {{...}}
+// variable the template's layers refer to
+func (t *Template) ReferencedVariables() []string {
+	seen := make(map[string]bool)
+
+	collect := func(text string) {
+		for _, match := range variableReference.FindAllStringSubmatch(text, -1) {
+			if name := referencedName(match[1]); name != "" {
+				seen[name] = true
+			}
+		}
+	}
+
+	for _, layer := range t.Layers {
+		collect(layer.Source)
+		for _, source := range layer.Sources {
+			collect(source)
+		}
+		collect(layer.Fallback)
+		collect(layer.Content)
+		for _, content := range layer.ContentCases {
+			collect(content)
+		}
+		if layer.ContentField != "" {
+			seen[layer.ContentField] = true
+		}
+		collect(layer.Condition)
+		collect(layer.Count)
+		collect(layer.Max)
+		collect(layer.Color)
+		collect(layer.EmptyColor)
+		collect(layer.SourceSheet)
+		collect(layer.SheetIndex)
+		collect(layer.TrimColor)
+
+		if layer.Type == "text" {
+			font := t.LayerFont(layer)
+			collect(font.Family)
+			collect(font.Color)
+			collect(font.FillImage)
+			if font.FillGradient != nil {
+				for _, stop := range font.FillGradient.Colors {
+					collect(stop)
+				}
+			}
+			if size, ok := font.Size.(string); ok {
+				collect(size)
+			}
+		}
+	}
+	collect(t.Collector.Format)
+	if t.VariantField != "" {
+		seen[t.VariantField] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// referencedName extracts the variable name from a token body, unwrapping
+// transforms like "pad:card.number:3" or "map:colors:card.rarity" and
+// expressions like "mtg.color == 'x' ? ..."
+func referencedName(token string) string {
+	token = strings.TrimSpace(token)
+	if rest, isMap := strings.CutPrefix(token, "map:"); isMap {
+		_, token, _ = strings.Cut(rest, ":")
+		token = strings.TrimSpace(token)
+	} else if match := transformPrefix.FindStringSubmatch(token); match != nil {
+		token = match[1]
+	}
+	return identifierPrefix.FindString(token)
+}
@@ -11,12 +11,102 @@ type CardStyleInfo struct {
 	Version     string
 	Source      string // "embedded", "workspace", "user", or file path
 	Extends     string // Base template it extends
+	Default     bool   // Marked as the TCG's default cardstyle
+}
+
+// FontInfo describes one registered font face
+type FontInfo struct {
+	Family string
+	Style  string // "Regular", "Bold", "Italic" or "Bold Italic"
+	Source string // Font file path, "registered" or "built-in"
+}
+
+// ManifestEntry describes a single rendered card in the output manifest
+type ManifestEntry struct {
+	Source    string `json:"source"`
+	Output    string `json:"output"`
+	TCG       string `json:"tcg"`
+	CardStyle string `json:"cardstyle"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// SetStats tallies the cards under a directory for a set overview
+type SetStats struct {
+	Cards       int            `json:"cards"`
+	ByRarity    map[string]int `json:"by_rarity"`
+	ByType      map[string]int `json:"by_type"`
+	ByCardStyle map[string]int `json:"by_cardstyle"` // "tcg/cardstyle", or the card's own template file
+	MissingArt  []string       `json:"missing_art"`  // Cards without card.artwork or whose local artwork file doesn't exist
+	Skipped     []string       `json:"skipped"`      // Markdown files that aren't cards or failed to parse
+}
+
+// CacheStats counts lookups answered from a cache and lookups that loaded
+type CacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// HitRate returns the fraction of lookups answered from the cache
+func (c CacheStats) HitRate() float64 {
+	if c.Hits+c.Misses == 0 {
+		return 0
+	}
+	return float64(c.Hits) / float64(c.Hits+c.Misses)
+}
+
+// BatchSummary reports on a generation run
+type BatchSummary struct {
+	Cards          int        `json:"cards"`
+	Rendered       int        `json:"rendered"`
+	Validated      int        `json:"validated"` // Validated but not rendered (validate-only)
+	Skipped        int        `json:"skipped"`   // Not matching the card filter
+	Failed         int        `json:"failed"`
+	Seconds        float64    `json:"seconds"` // From the first card starting to the last finishing
+	Slowest        string     `json:"slowest,omitempty"`
+	SlowestSeconds float64    `json:"slowest_seconds,omitempty"`
+	ImageCache     CacheStats `json:"image_cache"`
+	TemplateCache  CacheStats `json:"template_cache"`
 }
 
 // Config holds configuration for the card generator
 type Config struct {
-	TemplateDir  string
-	OutputDir    string
-	ValidateOnly bool
-	Verbose      bool
+	TemplateDirs   []string // Extra template directories, searched in order; earlier directories win
+	OutputDir      string
+	OutputRoot     string // Single output directory mirroring the input tree (overrides per-file OutputDir)
+	InputRoot      string // Root the mirrored output paths are relative to
+	ValidateOnly   bool
+	Verbose        bool
+	Quiet          bool
+	Proof          bool     // Also write *-proof.png with bleed/trim/safe guides
+	CropMarks      bool     // Also write *-crop.png with corner crop marks in the bleed
+	ThumbnailWidth int      // Also write *.thumb.png downscaled to this width (0 disables)
+	SVG            bool     // Also write *.svg with vector text and embedded images
+	Serialize      int      // Render this many numbered copies (card.print_this 1..N) as name-01.png, ... (0 disables)
+	Strict         bool     // Treat template warnings as errors
+	StrictImages   bool     // Fail cards whose images can't be loaded instead of drawing placeholders
+	StrictTCG      bool     // Fail cards whose card.tcg is missing or has no cardstyles instead of defaulting to mtg
+	Language       string   // Localized body section to render (overrides card.lang)
+	Filter         string   // Condition a card must meet to be generated, e.g. "{{card.rarity}} in [rare, mythic]"
+	EmojiFont      string   // TTF drawn for emoji and other characters missing from a layer's font
+	Seed           int64    // Seed for any randomized rendering (placeholder tints, etc.)
+	ExpandEnv      bool     // Expand ${VAR} references in image sources
+	EmbedSRGB      bool     // Tag card PNGs with the sRGB color space for print
+	OnlyLayers     []string // Render only these layers (e.g. for print plates); empty renders all
+	SkipLayers     []string // Layers left out of the render
+	Resample       string   // Image scaling quality: "nearest", "bilinear" (default) or "catmullrom"
+	Sharpen        bool     // Unsharp mask images that were scaled down
+	Scale          float64  // Render at this multiple of the template dimensions (0 or 1 = as designed)
+	LayerCache     bool     // Reuse layers that draw identically on several cards instead of redrawing them
+
+	// Template variables set for every card, overriding the card's own values
+	// (e.g. "card.set": "PROMO")
+	Variables map[string]string
+
+	// Missing-image placeholder style (empty values keep the default gray box)
+	PlaceholderFill      string // Hex fill color
+	PlaceholderBorder    string // Hex border color, or "none"
+	PlaceholderTextColor string // Hex label color
+	PlaceholderNoLabel   bool   // Omit the "Missing: file" label
 }